@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Ability struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	EffectEntries []VerboseEffect `json:"effect_entries"`
+	Pokemon       []struct {
+		IsHidden bool             `json:"is_hidden"`
+		Slot     int              `json:"slot"`
+		Pokemon  NamedAPIResource `json:"pokemon"`
+	} `json:"pokemon"`
+}
+
+func commandAbility(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: ability <name>")
+
+		return nil
+	}
+
+	ability, err := Get[Ability](ctx, c.Client, c.Client.URL("ability", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Name: " + ability.Name)
+
+	if effect, ok := englishEffect(ability.EffectEntries); ok {
+		fmt.Println("Short effect: " + cleanFlavorText(effect.ShortEffect))
+		fmt.Println("Effect: " + cleanFlavorText(effect.Effect))
+	}
+
+	names := make([]string, 0, len(ability.Pokemon))
+
+	for _, entry := range ability.Pokemon {
+		if entry.IsHidden {
+			names = append(names, entry.Pokemon.Name+" (hidden)")
+		} else {
+			names = append(names, entry.Pokemon.Name)
+		}
+	}
+
+	fmt.Println("Pokemon with this ability (" + fmt.Sprint(len(names)) + "):")
+	fmt.Print(wrapList(names, 80, "  "))
+
+	return nil
+}
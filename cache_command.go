@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+func commandCache(ctx context.Context, conf *config) error {
+	if len(conf.RawArgs) < 1 {
+		fmt.Println("Usage: cache list|stats|purge <prefix>|refresh <url>")
+
+		return nil
+	}
+
+	args := conf.RawArgs[1:]
+
+	switch strings.ToLower(conf.RawArgs[0]) {
+	case "list":
+		return cacheList(conf)
+	case "stats":
+		return cacheStats(conf)
+	case "purge":
+		if len(args) < 1 {
+			fmt.Println("Usage: cache purge <prefix>")
+
+			return nil
+		}
+
+		return cachePurge(conf, args[0])
+	case "refresh":
+		if len(args) < 1 {
+			fmt.Println("Usage: cache refresh <url>")
+
+			return nil
+		}
+
+		return cacheRefresh(ctx, conf, args[0])
+	default:
+		fmt.Println("Unknown cache command: " + conf.RawArgs[0])
+
+		return nil
+	}
+}
+
+func cacheList(conf *config) error {
+	ranger, ok := conf.Client.Cache.(CacheRanger)
+	if !ok {
+		return errors.New("this cache backend cannot list its entries")
+	}
+
+	type row struct {
+		name    string
+		size    int
+		expires time.Time
+	}
+
+	rows := make([]row, 0)
+
+	ranger.Range(func(name string, data []byte, expires time.Time) bool {
+		// Prefer the freshness of the stored response over the backend expiry,
+		// which includes the revalidation window
+		var entry cachedResponse
+
+		if err := json.Unmarshal(data, &entry); err == nil && !entry.Expires.IsZero() {
+			expires = entry.Expires
+		}
+
+		rows = append(rows, row{name, len(data), expires})
+
+		return true
+	})
+
+	if len(rows) == 0 {
+		fmt.Println("The cache is empty")
+
+		return nil
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].name < rows[j].name
+	})
+
+	for _, r := range rows {
+		fmt.Printf("%10s  %-12s %s\n", formatBytes(int64(r.size)), formatRemaining(r.expires), r.name)
+	}
+
+	return nil
+}
+
+func cacheStats(conf *config) error {
+	statter, ok := conf.Client.Cache.(CacheStatter)
+	if !ok {
+		return errors.New("this cache backend does not keep statistics")
+	}
+
+	stats := statter.Stats()
+
+	fmt.Println("Entries: " + fmt.Sprint(stats.Entries))
+	fmt.Println("Bytes: " + formatBytes(stats.Bytes))
+	fmt.Println("Hits: " + fmt.Sprint(stats.Hits))
+	fmt.Println("Misses: " + fmt.Sprint(stats.Misses))
+	fmt.Println("Evictions: " + fmt.Sprint(stats.Evictions))
+
+	return nil
+}
+
+func cachePurge(conf *config, prefix string) error {
+	ranger, ok := conf.Client.Cache.(CacheRanger)
+	if !ok {
+		return errors.New("this cache backend cannot list its entries")
+	}
+
+	deleter, ok := conf.Client.Cache.(CacheDeleter)
+	if !ok {
+		return errors.New("this cache backend cannot delete entries")
+	}
+
+	matched := make([]string, 0)
+
+	ranger.Range(func(name string, data []byte, expires time.Time) bool {
+		if strings.HasPrefix(name, conf.Client.Resolve(prefix)) {
+			matched = append(matched, name)
+		}
+
+		return true
+	})
+
+	for _, name := range matched {
+		deleter.Delete(name)
+	}
+
+	fmt.Println("Purged " + fmt.Sprint(len(matched)) + " entries")
+
+	return nil
+}
+
+func cacheRefresh(ctx context.Context, conf *config, target string) error {
+	// Accepts "pokemon/pikachu" as shorthand for a path under the base URL
+	url := conf.Client.Resolve(target)
+
+	data, err := conf.Client.Refresh(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Refreshed " + url + " (" + formatBytes(int64(len(data))) + ")")
+
+	return nil
+}
+
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func formatRemaining(expires time.Time) string {
+	remaining := time.Until(expires)
+
+	switch {
+	case remaining <= 0:
+		return "stale"
+	case remaining > NeverExpire/2:
+		return "never"
+	default:
+		return remaining.Round(time.Second).String() + " left"
+	}
+}
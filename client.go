@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://pokeapi.co/api/v2/"
+
+	// Well within PokeAPI's fair use policy
+	defaultRequestsPerSecond = 5
+	defaultBurst             = 10
+
+	// Applies to each attempt, retries get a fresh timeout
+	defaultTimeout = 15 * time.Second
+
+	// Expired entries are kept around this long so they can be revalidated
+	revalidateWindow = 24 * time.Hour
+)
+
+type Client struct {
+	BaseURL              string
+	Cache                CacheBackend
+	HTTP                 *http.Client
+	TTL                  TTLPolicy
+	Retry                RetryPolicy
+	Limiter              *RateLimiter
+	Timeout              time.Duration
+	StaleWhileRevalidate bool
+
+	flights flightGroup
+}
+
+// Body is kept as raw JSON so it isn't base64 encoded inside the envelope
+type cachedResponse struct {
+	Body         json.RawMessage `json:"body"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Expires      time.Time       `json:"expires"`
+	NotFound     bool            `json:"not_found,omitempty"`
+}
+
+func (r *cachedResponse) Fresh() bool {
+	return time.Now().Before(r.Expires)
+}
+
+func NewClient(cache CacheBackend) *Client {
+	return &Client{
+		BaseURL: defaultBaseURL,
+		Cache:   cache,
+		HTTP:    &http.Client{},
+		TTL:     DefaultTTLPolicy(),
+		Retry:   DefaultRetryPolicy(),
+		Limiter: NewRateLimiter(defaultRequestsPerSecond, defaultBurst),
+		Timeout: defaultTimeout,
+	}
+}
+
+// TakeWaited returns the time spent waiting on the rate limiter since the
+// last call
+func (c *Client) TakeWaited() time.Duration {
+	return c.Limiter.TakeWaited()
+}
+
+// URL builds a resource URL under the configured base, e.g. URL("pokemon", "pikachu").
+// Each part is escaped so user input can't add path segments or a query, a
+// trailing slash is kept for list endpoints like URL("location-area/")
+func (c *Client) URL(parts ...string) string {
+	escaped := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		if trimmed, ok := strings.CutSuffix(part, "/"); ok {
+			escaped = append(escaped, url.PathEscape(trimmed)+"/")
+		} else {
+			escaped = append(escaped, url.PathEscape(part))
+		}
+	}
+
+	return c.Resolve(strings.Join(escaped, "/"))
+}
+
+// Resolve points a relative path or a PokeAPI link at the configured base URL,
+// so pagination and nested resource links follow a mirror too
+func (c *Client) Resolve(rawURL string) string {
+	base := strings.TrimSuffix(c.BaseURL, "/") + "/"
+
+	if base == "/" {
+		base = defaultBaseURL
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		return base + strings.TrimPrefix(rawURL, "/")
+	}
+
+	if strings.HasPrefix(rawURL, base) {
+		return rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	_, rest, ok := strings.Cut(parsed.EscapedPath(), "/api/v2/")
+	if !ok {
+		return rawURL
+	}
+
+	if parsed.RawQuery != "" {
+		rest += "?" + parsed.RawQuery
+	}
+
+	return base + rest
+}
+
+// Get fetches a PokeAPI resource through the client cache and decodes it
+func Get[T any](ctx context.Context, c *Client, url string) (T, error) {
+	var result T
+
+	data, err := c.Fetch(ctx, url)
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		// Drop the body so the next call fetches it again
+		if deleter, ok := c.Cache.(CacheDeleter); ok {
+			deleter.Delete(c.Resolve(url))
+		}
+
+		return result, &DecodeError{URL: c.Resolve(url), Err: err}
+	}
+
+	return result, nil
+}
+
+// Fetch returns the raw body for url, from the cache when it is fresh
+func (c *Client) Fetch(ctx context.Context, url string) ([]byte, error) {
+	url = c.Resolve(url)
+
+	var cached *cachedResponse
+
+	if data, ok := c.Cache.Get(url); ok {
+		var entry cachedResponse
+
+		// Entries written before bodies were raw JSON hold a base64 string
+		if err := json.Unmarshal(data, &entry); err == nil && !bytes.HasPrefix(entry.Body, []byte(`"`)) {
+			cached = &entry
+		}
+	}
+
+	if cached != nil && cached.Fresh() {
+		if cached.NotFound {
+			return nil, newNotFoundError(url)
+		}
+
+		return cached.Body, nil
+	}
+
+	fetch := func(ctx context.Context) ([]byte, error) {
+		return c.flights.Do(ctx, url, func(ctx context.Context) ([]byte, error) {
+			return c.fetchAndStore(ctx, url, cached)
+		})
+	}
+
+	if cached != nil && c.StaleWhileRevalidate {
+		// The refresh outlives the command that triggered it
+		go fetch(context.WithoutCancel(ctx))
+
+		return cached.Body, nil
+	}
+
+	return fetch(ctx)
+}
+
+// Refresh fetches url from the server even when the cached copy is fresh,
+// the cached copy is only replaced once the fetch succeeds
+func (c *Client) Refresh(ctx context.Context, url string) ([]byte, error) {
+	url = c.Resolve(url)
+
+	return c.flights.Do(ctx, url, func(ctx context.Context) ([]byte, error) {
+		return c.fetchAndStore(ctx, url, nil)
+	})
+}
+
+func (c *Client) fetchAndStore(ctx context.Context, url string, cached *cachedResponse) ([]byte, error) {
+	attempts := max(c.Retry.MaxAttempts, 1)
+
+	for retry := 1; ; retry++ {
+		data, err := c.fetchOnce(ctx, url, cached)
+
+		// A cancelled command is never retried, a timed out attempt may be
+		if err == nil || retry >= attempts || ctx.Err() != nil || !c.Retry.Retryable(err) {
+			return data, err
+		}
+
+		timer := time.NewTimer(c.Retry.Delay(retry, err))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, &NetworkError{URL: url, Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) fetchOnce(ctx context.Context, url string, cached *cachedResponse) ([]byte, error) {
+	if _, err := c.Limiter.Wait(ctx); err != nil {
+		return nil, &NetworkError{URL: url, Err: err}
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &RequestError{URL: url, Err: err}
+	}
+
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, &NetworkError{URL: url, Err: err}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil && !cached.NotFound {
+		c.store(url, *cached, c.TTL.For(url))
+
+		return cached.Body, nil
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		c.store(url, cachedResponse{NotFound: true}, c.TTL.ForNotFound())
+
+		return nil, newNotFoundError(url)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// 503s may carry Retry-After as well as 429s
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, &RateLimitedError{URL: url, RetryAfter: retryAfter}
+		}
+
+		return nil, &ServerError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status, RetryAfter: retryAfter}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &NetworkError{URL: url, Err: err}
+	}
+
+	// An error page served with a 200 must not be cached as the resource
+	if !json.Valid(body) {
+		return nil, &DecodeError{URL: url, Err: errors.New("response is not valid JSON")}
+	}
+
+	c.store(url, cachedResponse{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, c.TTL.For(url))
+
+	return body, nil
+}
+
+func (c *Client) store(url string, entry cachedResponse, ttl time.Duration) {
+	entry.Expires = time.Now().Add(ttl)
+
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	// Negative entries have nothing to revalidate
+	if entry.NotFound {
+		c.Cache.Set(url, encoded, ttl)
+	} else {
+		c.Cache.Set(url, encoded, ttl+revalidateWindow)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DiskCache stores one file per entry, values must be JSON since they are
+// embedded as is
+type DiskCache struct {
+	dir    string
+	mu     sync.Mutex
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type diskEntry struct {
+	Key     string          `json:"key"`
+	Expires time.Time       `json:"expires"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+
+		dir = filepath.Join(base, "pokedex")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(name string) string {
+	sum := sha256.Sum256([]byte(name))
+
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (d *DiskCache) Get(name string) ([]byte, bool) {
+	data, ok := d.get(name)
+
+	if ok {
+		d.hits.Add(1)
+	} else {
+		d.misses.Add(1)
+	}
+
+	return data, ok
+}
+
+func (d *DiskCache) get(name string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	raw, err := os.ReadFile(d.path(name))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry diskEntry
+
+	// A different key means a hash collision, treat it as a miss
+	if err := json.Unmarshal(raw, &entry); err != nil || entry.Key != name {
+		return nil, false
+	}
+
+	if time.Now().After(entry.Expires) {
+		os.Remove(d.path(name))
+
+		return nil, false
+	}
+
+	return entry.Data, true
+}
+
+func (d *DiskCache) Set(name string, data []byte, duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	encoded, err := json.Marshal(diskEntry{
+		Key:     name,
+		Expires: time.Now().Add(duration),
+		Data:    data,
+	})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first so readers never see a partial entry
+	tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+
+	if _, err := tmp.Write(encoded); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), d.path(name)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (d *DiskCache) Range(fn func(name string, data []byte, expires time.Time) bool) {
+	paths, err := filepath.Glob(filepath.Join(d.dir, "*.json"))
+	if err != nil {
+		return
+	}
+
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var entry diskEntry
+
+		if err := json.Unmarshal(raw, &entry); err != nil || time.Now().After(entry.Expires) {
+			continue
+		}
+
+		if !fn(entry.Key, entry.Data, entry.Expires) {
+			return
+		}
+	}
+}
+
+func (d *DiskCache) Delete(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	os.Remove(d.path(name))
+}
+
+func (d *DiskCache) Stats() CacheStats {
+	stats := CacheStats{
+		Hits:   d.hits.Load(),
+		Misses: d.misses.Load(),
+	}
+
+	d.Range(func(name string, data []byte, expires time.Time) bool {
+		stats.Entries++
+		stats.Bytes += int64(len(data))
+
+		return true
+	})
+
+	return stats
+}
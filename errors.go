@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type NotFoundError struct {
+	Resource string
+	Name     string
+	URL      string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Resource == "" || e.Name == "" {
+		return "not found: " + e.URL
+	}
+
+	return "unknown " + e.Resource + ": " + e.Name
+}
+
+func newNotFoundError(rawURL string) *NotFoundError {
+	err := &NotFoundError{URL: rawURL}
+
+	parsed, parseErr := url.Parse(rawURL)
+	if parseErr != nil {
+		return err
+	}
+
+	// .../api/v2/{resource}/{id or name}/
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+
+	if len(segments) >= 2 {
+		err.Resource = segments[len(segments)-2]
+		err.Name = segments[len(segments)-1]
+	}
+
+	return err
+}
+
+type RateLimitedError struct {
+	URL        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return "rate limited by " + e.URL
+}
+
+// ServerError is any unexpected status, usually a 5xx
+type ServerError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *ServerError) Error() string {
+	return "unexpected status " + e.Status + " from " + e.URL
+}
+
+type DecodeError struct {
+	URL string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "decoding " + e.URL + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return "requesting " + e.URL + ": " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// RequestError means no request could be built for the URL, e.g. a malformed
+// mirror base URL
+type RequestError struct {
+	URL string
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return "building request for " + e.URL + ": " + e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Retry-After is either a number of seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+func describeError(err error) string {
+	var notFound *NotFoundError
+	var rateLimited *RateLimitedError
+	var server *ServerError
+	var decode *DecodeError
+	var network *NetworkError
+	var request *RequestError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Cancelled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "PokeAPI took too long to respond, try again"
+	case errors.As(err, &notFound):
+		if notFound.Resource == "" {
+			return "That doesn't exist"
+		}
+
+		return "Unknown " + notFound.Resource + ": " + notFound.Name
+	case errors.As(err, &rateLimited):
+		if rateLimited.RetryAfter > 0 {
+			return "PokeAPI is rate limiting us, try again in " + rateLimited.RetryAfter.Round(time.Second).String()
+		}
+
+		return "PokeAPI is rate limiting us, try again shortly"
+	case errors.As(err, &server):
+		return fmt.Sprintf("PokeAPI had a problem (%s), try again later", server.Status)
+	case errors.As(err, &decode):
+		return "PokeAPI sent a response we couldn't understand"
+	case errors.As(err, &network):
+		return "Couldn't reach PokeAPI: " + network.Err.Error()
+	case errors.As(err, &request):
+		return "Invalid URL: " + request.URL
+	default:
+		return err.Error()
+	}
+}
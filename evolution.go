@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type EvolutionChain struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+type ChainLink struct {
+	IsBaby           bool              `json:"is_baby"`
+	Species          NamedAPIResource  `json:"species"`
+	EvolutionDetails []EvolutionDetail `json:"evolution_details"`
+	EvolvesTo        []ChainLink       `json:"evolves_to"`
+}
+
+type EvolutionDetail struct {
+	Trigger               NamedAPIResource  `json:"trigger"`
+	Item                  *NamedAPIResource `json:"item"`
+	Gender                *int              `json:"gender"`
+	HeldItem              *NamedAPIResource `json:"held_item"`
+	KnownMove             *NamedAPIResource `json:"known_move"`
+	KnownMoveType         *NamedAPIResource `json:"known_move_type"`
+	Location              *NamedAPIResource `json:"location"`
+	MinLevel              *int              `json:"min_level"`
+	MinHappiness          *int              `json:"min_happiness"`
+	MinBeauty             *int              `json:"min_beauty"`
+	MinAffection          *int              `json:"min_affection"`
+	NeedsOverworldRain    bool              `json:"needs_overworld_rain"`
+	PartySpecies          *NamedAPIResource `json:"party_species"`
+	PartyType             *NamedAPIResource `json:"party_type"`
+	RelativePhysicalStats *int              `json:"relative_physical_stats"`
+	TimeOfDay             string            `json:"time_of_day"`
+	TradeSpecies          *NamedAPIResource `json:"trade_species"`
+	TurnUpsideDown        bool              `json:"turn_upside_down"`
+}
+
+// Describe renders the trigger and its conditions, e.g. "level-up: level 16"
+func (d EvolutionDetail) Describe() string {
+	conditions := make([]string, 0)
+
+	if d.MinLevel != nil {
+		conditions = append(conditions, "level "+fmt.Sprint(*d.MinLevel))
+	}
+
+	if d.Item != nil {
+		conditions = append(conditions, "with "+d.Item.Name)
+	}
+
+	if d.HeldItem != nil {
+		conditions = append(conditions, "holding "+d.HeldItem.Name)
+	}
+
+	if d.MinHappiness != nil {
+		conditions = append(conditions, "friendship "+fmt.Sprint(*d.MinHappiness)+"+")
+	}
+
+	if d.MinAffection != nil {
+		conditions = append(conditions, "affection "+fmt.Sprint(*d.MinAffection)+"+")
+	}
+
+	if d.MinBeauty != nil {
+		conditions = append(conditions, "beauty "+fmt.Sprint(*d.MinBeauty)+"+")
+	}
+
+	if d.TimeOfDay != "" {
+		conditions = append(conditions, "during the "+d.TimeOfDay)
+	}
+
+	if d.Location != nil {
+		conditions = append(conditions, "at "+d.Location.Name)
+	}
+
+	if d.KnownMove != nil {
+		conditions = append(conditions, "knowing "+d.KnownMove.Name)
+	}
+
+	if d.KnownMoveType != nil {
+		conditions = append(conditions, "knowing a "+d.KnownMoveType.Name+" move")
+	}
+
+	if d.Gender != nil {
+		// PokeAPI gender ids: 1 is female, 2 is male
+		if *d.Gender == 1 {
+			conditions = append(conditions, "female")
+		} else {
+			conditions = append(conditions, "male")
+		}
+	}
+
+	if d.PartySpecies != nil {
+		conditions = append(conditions, "with "+d.PartySpecies.Name+" in the party")
+	}
+
+	if d.PartyType != nil {
+		conditions = append(conditions, "with a "+d.PartyType.Name+" type in the party")
+	}
+
+	if d.TradeSpecies != nil {
+		conditions = append(conditions, "for "+d.TradeSpecies.Name)
+	}
+
+	if d.RelativePhysicalStats != nil {
+		switch *d.RelativePhysicalStats {
+		case 1:
+			conditions = append(conditions, "attack > defense")
+		case -1:
+			conditions = append(conditions, "attack < defense")
+		default:
+			conditions = append(conditions, "attack = defense")
+		}
+	}
+
+	if d.NeedsOverworldRain {
+		conditions = append(conditions, "while raining")
+	}
+
+	if d.TurnUpsideDown {
+		conditions = append(conditions, "console upside down")
+	}
+
+	if len(conditions) == 0 {
+		return d.Trigger.Name
+	}
+
+	return d.Trigger.Name + ": " + strings.Join(conditions, ", ")
+}
+
+func renderEvolutionTree(root ChainLink) string {
+	var b strings.Builder
+
+	b.WriteString(root.Species.Name + "\n")
+
+	renderEvolutionBranches(&b, root.EvolvesTo, "")
+
+	return b.String()
+}
+
+func renderEvolutionBranches(b *strings.Builder, links []ChainLink, prefix string) {
+	for i, link := range links {
+		branch, indent := "├── ", "│   "
+
+		if i == len(links)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		// The same evolution can have different requirements per game
+		methods := make([]string, 0, len(link.EvolutionDetails))
+
+		for _, detail := range link.EvolutionDetails {
+			methods = append(methods, detail.Describe())
+		}
+
+		line := prefix + branch + link.Species.Name
+
+		if len(methods) > 0 {
+			line += " (" + strings.Join(methods, " or ") + ")"
+		}
+
+		b.WriteString(line + "\n")
+
+		renderEvolutionBranches(b, link.EvolvesTo, prefix+indent)
+	}
+}
+
+func commandEvolution(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: evolution <pokemon>")
+
+		return nil
+	}
+
+	species, err := Get[PokemonSpecies](ctx, c.Client, c.Client.URL("pokemon-species", c.Args[0]))
+
+	// Forms like deoxys-attack are Pokemon, not species, so go through the Pokemon
+	var notFound *NotFoundError
+
+	if errors.As(err, &notFound) {
+		pokemon, pokemonErr := Get[Pokemon](ctx, c.Client, c.Client.URL("pokemon", c.Args[0]))
+		if pokemonErr != nil {
+			return pokemonErr
+		}
+
+		species, err = Get[PokemonSpecies](ctx, c.Client, pokemon.Species.URL)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	chain, err := Get[EvolutionChain](ctx, c.Client, species.EvolutionChain.URL)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(renderEvolutionTree(chain.Chain))
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type methodEncounters struct {
+	Method string
+	Rate   int
+	Rows   []exploreRow
+}
+
+type exploreRow struct {
+	Pokemon    string
+	MinLevel   int
+	MaxLevel   int
+	Chance     int
+	Conditions string
+}
+
+// parseExploreArgs accepts the area plus --version=<game> or --version <game>
+func parseExploreArgs(args []string) (string, string) {
+	area, version := "", ""
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case strings.HasPrefix(args[i], "--version="):
+			version = strings.TrimPrefix(args[i], "--version=")
+		case args[i] == "--version" && i+1 < len(args):
+			version = args[i+1]
+
+			i++
+		case area == "":
+			area = args[i]
+		}
+	}
+
+	return area, version
+}
+
+// encounterTable groups an area's encounters in one game by method, merging
+// the level ranges and odds of a Pokemon under the same conditions
+func encounterTable(area LocationArea, version string) []methodEncounters {
+	rates := make(map[string]int)
+
+	for _, rate := range area.EncounterMethodRates {
+		for _, detail := range rate.VersionDetails {
+			if detail.Version.Name == version {
+				rates[rate.EncounterMethod.Name] = detail.Rate
+			}
+		}
+	}
+
+	byMethod := make(map[string]map[string]*exploreRow)
+
+	for _, encounter := range area.PokemonEncounters {
+		for _, versionDetail := range encounter.VersionDetails {
+			if versionDetail.Version.Name != version {
+				continue
+			}
+
+			for _, detail := range versionDetail.EncounterDetails {
+				conditions := make([]string, 0, len(detail.ConditionValues))
+
+				for _, condition := range detail.ConditionValues {
+					conditions = append(conditions, condition.Name)
+				}
+
+				sort.Strings(conditions)
+
+				rows, ok := byMethod[detail.Method.Name]
+				if !ok {
+					rows = make(map[string]*exploreRow)
+					byMethod[detail.Method.Name] = rows
+				}
+
+				key := encounter.Pokemon.Name + "/" + strings.Join(conditions, ",")
+
+				row, ok := rows[key]
+				if !ok {
+					row = &exploreRow{
+						Pokemon:    encounter.Pokemon.Name,
+						MinLevel:   detail.MinLevel,
+						MaxLevel:   detail.MaxLevel,
+						Conditions: strings.Join(conditions, ", "),
+					}
+
+					rows[key] = row
+				}
+
+				row.MinLevel = min(row.MinLevel, detail.MinLevel)
+				row.MaxLevel = max(row.MaxLevel, detail.MaxLevel)
+				row.Chance += detail.Chance
+			}
+		}
+	}
+
+	table := make([]methodEncounters, 0, len(byMethod))
+
+	for method, rows := range byMethod {
+		group := methodEncounters{Method: method, Rate: rates[method]}
+
+		for _, row := range rows {
+			group.Rows = append(group.Rows, *row)
+		}
+
+		sort.Slice(group.Rows, func(i, j int) bool {
+			if group.Rows[i].Chance != group.Rows[j].Chance {
+				return group.Rows[i].Chance > group.Rows[j].Chance
+			}
+
+			return group.Rows[i].Pokemon+group.Rows[i].Conditions < group.Rows[j].Pokemon+group.Rows[j].Conditions
+		})
+
+		table = append(table, group)
+	}
+
+	sort.Slice(table, func(i, j int) bool {
+		return table[i].Method < table[j].Method
+	})
+
+	return table
+}
+
+func printEncounterTable(area LocationArea, version string) {
+	table := encounterTable(area, version)
+
+	if len(table) == 0 {
+		fmt.Println("No Pokemon found in this area in " + version)
+
+		return
+	}
+
+	for _, group := range table {
+		if group.Rate > 0 {
+			fmt.Println(group.Method + " (encounter rate " + fmt.Sprint(group.Rate) + "%):")
+		} else {
+			fmt.Println(group.Method + ":")
+		}
+
+		for _, row := range group.Rows {
+			line := fmt.Sprintf("  %-16s %-9s %3d%%", row.Pokemon, formatLevels(row.MinLevel, row.MaxLevel), row.Chance)
+
+			if row.Conditions != "" {
+				line += "  " + row.Conditions
+			}
+
+			fmt.Println(line)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// commandRunner runs one REPL command at a time so that an interrupt can
+// cancel just that command
+type commandRunner struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (r *commandRunner) Run(command cliCommand) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.cancel = nil
+		r.mu.Unlock()
+	}()
+
+	return command.callback(ctx)
+}
+
+// Interrupt cancels the running command, reporting false when there was none
+func (r *commandRunner) Interrupt() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel == nil {
+		return false
+	}
+
+	r.cancel()
+
+	return true
+}
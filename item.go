@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type Item struct {
+	ID            int                `json:"id"`
+	Name          string             `json:"name"`
+	Cost          int                `json:"cost"`
+	FlingPower    *int               `json:"fling_power"`
+	FlingEffect   *NamedAPIResource  `json:"fling_effect"`
+	Category      NamedAPIResource   `json:"category"`
+	Attributes    []NamedAPIResource `json:"attributes"`
+	EffectEntries []VerboseEffect    `json:"effect_entries"`
+	HeldByPokemon []struct {
+		Pokemon NamedAPIResource `json:"pokemon"`
+	} `json:"held_by_pokemon"`
+}
+
+type Berry struct {
+	ID               int              `json:"id"`
+	Name             string           `json:"name"`
+	GrowthTime       int              `json:"growth_time"`
+	MaxHarvest       int              `json:"max_harvest"`
+	NaturalGiftPower int              `json:"natural_gift_power"`
+	NaturalGiftType  NamedAPIResource `json:"natural_gift_type"`
+	Size             int              `json:"size"`
+	Smoothness       int              `json:"smoothness"`
+	SoilDryness      int              `json:"soil_dryness"`
+	Firmness         NamedAPIResource `json:"firmness"`
+	Flavors          []struct {
+		Potency int              `json:"potency"`
+		Flavor  NamedAPIResource `json:"flavor"`
+	} `json:"flavors"`
+	Item NamedAPIResource `json:"item"`
+}
+
+func printItem(item Item) {
+	fmt.Println("Name: " + item.Name)
+	fmt.Println("Category: " + item.Category.Name)
+	fmt.Println("Cost: " + fmt.Sprint(item.Cost))
+	fmt.Println("Fling power: " + formatOptional(item.FlingPower))
+
+	if item.FlingEffect != nil {
+		fmt.Println("Fling effect: " + item.FlingEffect.Name)
+	}
+
+	if effect, ok := englishEffect(item.EffectEntries); ok {
+		fmt.Println("Effect: " + cleanFlavorText(effect.Effect))
+	}
+
+	if len(item.HeldByPokemon) > 0 {
+		names := make([]string, 0, len(item.HeldByPokemon))
+
+		for _, held := range item.HeldByPokemon {
+			names = append(names, held.Pokemon.Name)
+		}
+
+		fmt.Println("Held by wild Pokemon:")
+		fmt.Print(wrapList(names, 80, "  "))
+	}
+}
+
+func commandItem(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: item <name>")
+
+		return nil
+	}
+
+	item, err := Get[Item](ctx, c.Client, c.Client.URL("item", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	printItem(item)
+
+	return nil
+}
+
+func commandBerry(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: berry <name>")
+
+		return nil
+	}
+
+	berry, err := Get[Berry](ctx, c.Client, c.Client.URL("berry", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	// Cost, category and effect live on the berry's item
+	item, err := Get[Item](ctx, c.Client, berry.Item.URL)
+	if err != nil {
+		return err
+	}
+
+	printItem(item)
+
+	fmt.Println("Firmness: " + berry.Firmness.Name)
+	fmt.Println("Growth time: " + fmt.Sprint(berry.GrowthTime) + " hours per stage")
+	fmt.Println("Max harvest: " + fmt.Sprint(berry.MaxHarvest))
+	fmt.Println("Size: " + fmt.Sprint(berry.Size) + " mm")
+	fmt.Println("Smoothness: " + fmt.Sprint(berry.Smoothness))
+	fmt.Println("Natural gift: " + berry.NaturalGiftType.Name + " " + fmt.Sprint(berry.NaturalGiftPower))
+
+	fmt.Println("Flavors:")
+
+	for _, flavor := range berry.Flavors {
+		if flavor.Potency > 0 {
+			fmt.Println(" - " + flavor.Flavor.Name + ": " + fmt.Sprint(flavor.Potency))
+		}
+	}
+
+	return nil
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"container/list"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"math/rand"
-	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,12 +20,31 @@ type Cache struct {
 	expires time.Time
 	data    []byte
 	mu      sync.RWMutex
+	element *list.Element
 }
 
 type CacheStore interface {
 	Expired() bool
 }
 
+type CacheBackend interface {
+	Get(name string) ([]byte, bool)
+	Set(name string, data []byte, duration time.Duration)
+}
+
+// CacheRanger is implemented by backends that can enumerate their live entries
+type CacheRanger interface {
+	Range(fn func(name string, data []byte, expires time.Time) bool)
+}
+
+type CacheDeleter interface {
+	Delete(name string)
+}
+
+type CacheStatter interface {
+	Stats() CacheStats
+}
+
 type Caches map[string]*Cache
 
 func (c *Cache) Expired() bool {
@@ -58,7 +80,7 @@ func (c *Caches) Get(name string) ([]byte, bool) {
 		*c = make(Caches)
 	}
 
-	if cache, ok := (*c)[name]; ok {
+	if cache, ok := (*c)[name]; ok && !cache.Expired() {
 		cache.mu.RLock()
 		defer cache.mu.RUnlock()
 
@@ -68,14 +90,24 @@ func (c *Caches) Get(name string) ([]byte, bool) {
 	return nil, false
 }
 
-type Location struct {
-	Count    int    `json:"count"`
-	Next     string `json:"next"`
-	Previous string `json:"previous"`
-	Results  []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"results"`
+type NamedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type NamedAPIResourceList struct {
+	Count    int                `json:"count"`
+	Next     string             `json:"next"`
+	Previous string             `json:"previous"`
+	Results  []NamedAPIResource `json:"results"`
+}
+
+type EncounterDetail struct {
+	Chance          int                `json:"chance"`
+	ConditionValues []NamedAPIResource `json:"condition_values"`
+	MaxLevel        int                `json:"max_level"`
+	MinLevel        int                `json:"min_level"`
+	Method          NamedAPIResource   `json:"method"`
 }
 
 type LocationArea struct {
@@ -112,18 +144,9 @@ type LocationArea struct {
 			URL  string `json:"url"`
 		} `json:"pokemon"`
 		VersionDetails []struct {
-			EncounterDetails []struct {
-				Chance          int   `json:"chance"`
-				ConditionValues []any `json:"condition_values"`
-				MaxLevel        int   `json:"max_level"`
-				Method          struct {
-					Name string `json:"name"`
-					URL  string `json:"url"`
-				} `json:"method"`
-				MinLevel int `json:"min_level"`
-			} `json:"encounter_details"`
-			MaxChance int `json:"max_chance"`
-			Version   struct {
+			EncounterDetails []EncounterDetail `json:"encounter_details"`
+			MaxChance        int               `json:"max_chance"`
+			Version          struct {
 				Name string `json:"name"`
 				URL  string `json:"url"`
 			} `json:"version"`
@@ -407,16 +430,21 @@ type config struct {
 	Map      struct {
 		Next     string
 		Previous string
+
+		// When set, map pages through this region's locations instead
+		Region string
+		Offset int
 	}
-	Redis   *Caches
+	Client  *Client
 	Args    []string
+	RawArgs []string
 	Pokemon map[string]Pokemon
 }
 
 type cliCommand struct {
 	name        string
 	description string
-	callback    func() error
+	callback    func(ctx context.Context) error
 }
 
 func cleanInput(text string) []string {
@@ -433,142 +461,6 @@ func cleanInput(text string) []string {
 	return clean
 }
 
-func ApiGetLocations(url string, conf *config) (Location, error) {
-	// GET https://pokeapi.co/api/v2/location/{id or name}/
-	if data, ok := (*conf.Redis).Get(url); ok {
-		var location Location
-
-		if err := json.Unmarshal(data, &location); err != nil {
-			return Location{}, err
-		}
-
-		return location, nil
-	}
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return Location{}, err
-	}
-
-	defer resp.Body.Close()
-
-	var location Location
-
-	if err := json.NewDecoder(resp.Body).Decode(&location); err != nil {
-		return Location{}, err
-	}
-
-	encoded, err := json.Marshal(location)
-
-	if err == nil {
-		(*conf.Redis).Set(url, encoded, 1*time.Hour)
-	}
-
-	return location, nil
-}
-
-func ApiGetLocationArea(url string, conf *config) (Location, error) {
-	// GET https://pokeapi.co/api/v2/location-area/{id or name}/
-	if data, ok := (*conf.Redis).Get(url); ok {
-		var location Location
-
-		if err := json.Unmarshal(data, &location); err != nil {
-			return Location{}, err
-		}
-
-		return location, nil
-	}
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return Location{}, err
-	}
-
-	defer resp.Body.Close()
-
-	var location Location
-
-	if err := json.NewDecoder(resp.Body).Decode(&location); err != nil {
-		return Location{}, err
-	}
-
-	encoded, err := json.Marshal(location)
-
-	if err == nil {
-		(*conf.Redis).Set(url, encoded, 1*time.Hour)
-	}
-
-	return location, nil
-}
-
-func ApiGetAreaDetails(url string, conf *config) (LocationArea, error) {
-	// GET https://pokeapi.co/api/v2/location-area/{id or name}/
-	if data, ok := (*conf.Redis).Get(url); ok {
-		var locationArea LocationArea
-
-		if err := json.Unmarshal(data, &locationArea); err != nil {
-			return LocationArea{}, err
-		}
-
-		return locationArea, nil
-	}
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return LocationArea{}, err
-	}
-
-	defer resp.Body.Close()
-
-	var locationArea LocationArea
-
-	if err := json.NewDecoder(resp.Body).Decode(&locationArea); err != nil {
-		return LocationArea{}, err
-	}
-
-	encoded, err := json.Marshal(locationArea)
-
-	if err == nil {
-		(*conf.Redis).Set(url, encoded, 1*time.Hour)
-	}
-
-	return locationArea, nil
-}
-
-func ApiGetPokemon(url string, conf *config) (Pokemon, error) {
-	// GET https://pokeapi.co/api/v2/pokemon/{id or name}/
-	if data, ok := (*conf.Redis).Get(url); ok {
-		var pokemon Pokemon
-
-		if err := json.Unmarshal(data, &pokemon); err != nil {
-			return Pokemon{}, err
-		}
-
-		return pokemon, nil
-	}
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return Pokemon{}, err
-	}
-
-	defer resp.Body.Close()
-
-	var pokemon Pokemon
-
-	if err := json.NewDecoder(resp.Body).Decode(&pokemon); err != nil {
-		return Pokemon{}, err
-	}
-
-	encoded, err := json.Marshal(pokemon)
-
-	if err == nil {
-		(*conf.Redis).Set(url, encoded, 1*time.Hour)
-	}
-
-	return pokemon, nil
-}
-
 func commandExit(conf *config) error {
 	fmt.Println("Closing the Pokedex... Goodbye!")
 
@@ -588,13 +480,17 @@ func commandHelp(conf *config) error {
 	return nil
 }
 
-func commandMapForward(conf *config) error {
+func commandMapForward(ctx context.Context, conf *config) error {
+	if conf.Map.Region != "" {
+		return regionMapForward(ctx, conf)
+	}
+
 	if conf.Map.Next == "" {
 		fmt.Println("You're on the last page")
 		return nil
 	}
 
-	location, err := ApiGetLocationArea(conf.Map.Next, conf)
+	location, err := Get[NamedAPIResourceList](ctx, conf.Client, conf.Map.Next)
 	if err != nil {
 		return err
 	}
@@ -609,13 +505,17 @@ func commandMapForward(conf *config) error {
 	return nil
 }
 
-func commandMapBack(conf *config) error {
+func commandMapBack(ctx context.Context, conf *config) error {
+	if conf.Map.Region != "" {
+		return regionMapBack(ctx, conf)
+	}
+
 	if conf.Map.Previous == "" {
 		fmt.Println("You're on the first page")
 		return nil
 	}
 
-	location, err := ApiGetLocationArea(conf.Map.Previous, conf)
+	location, err := Get[NamedAPIResourceList](ctx, conf.Client, conf.Map.Previous)
 	if err != nil {
 		return err
 	}
@@ -630,21 +530,29 @@ func commandMapBack(conf *config) error {
 	return nil
 }
 
-func commandExploreArea(conf *config) error {
-	if len(conf.Args) < 1 {
-		fmt.Println("Usage: explore <area>")
+func commandExploreArea(ctx context.Context, conf *config) error {
+	area, version := parseExploreArgs(conf.Args)
+
+	if area == "" {
+		fmt.Println("Usage: explore <area> [--version=<game>]")
 
 		return nil
 	}
 
-	area := conf.Args[0]
-
-	areaDetails, err := ApiGetAreaDetails("https://pokeapi.co/api/v2/location-area/"+area, conf)
+	areaDetails, err := Get[LocationArea](ctx, conf.Client, conf.Client.URL("location-area", area))
 
 	if err != nil {
 		return err
 	}
 
+	if version != "" {
+		fmt.Println("Exploring " + areaDetails.Name + " in " + version)
+
+		printEncounterTable(areaDetails, version)
+
+		return nil
+	}
+
 	fmt.Println("Exploring " + areaDetails.Name)
 
 	if len(areaDetails.PokemonEncounters) > 0 {
@@ -660,7 +568,7 @@ func commandExploreArea(conf *config) error {
 	return nil
 }
 
-func commandCatchPokemon(c *config) error {
+func commandCatchPokemon(ctx context.Context, c *config) error {
 	if len(c.Args) < 1 {
 		fmt.Println("Usage: catch <pokemon>")
 
@@ -671,7 +579,7 @@ func commandCatchPokemon(c *config) error {
 
 	fmt.Println("Throwing a Pokeball at " + pokemon + "...")
 
-	pokemonDetails, err := ApiGetPokemon("https://pokeapi.co/api/v2/pokemon/"+pokemon, c)
+	pokemonDetails, err := Get[Pokemon](ctx, c.Client, c.Client.URL("pokemon", pokemon))
 
 	if err != nil {
 		return err
@@ -681,6 +589,10 @@ func commandCatchPokemon(c *config) error {
 
 	fmt.Println("Base experience: " + fmt.Sprint(baseExp))
 
+	if baseExp <= 0 {
+		return fmt.Errorf("%s has no base experience to catch against", pokemon)
+	}
+
 	if rand.Intn(baseExp) < baseExp/4 {
 		fmt.Println(pokemon + " was caught!")
 
@@ -716,6 +628,16 @@ func commandInspectPokemon(c *config) error {
 		for _, type_ := range details.Types {
 			fmt.Println(" - " + type_.Type.Name)
 		}
+
+		fmt.Println("Abilities:")
+
+		for _, ability := range details.Abilities {
+			if ability.IsHidden {
+				fmt.Println(" - " + ability.Ability.Name + " (hidden)")
+			} else {
+				fmt.Println(" - " + ability.Ability.Name)
+			}
+		}
 	} else {
 		fmt.Println(pokemon + " has not been caught")
 	}
@@ -723,62 +645,183 @@ func commandInspectPokemon(c *config) error {
 	return nil
 }
 
+func envOr(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// envDefault parses key into target when its flag was left at zero
+func envDefault[T comparable](target *T, key string, parse func(string) (T, error)) error {
+	var zero T
+
+	value, ok := os.LookupEnv(key)
+	if *target != zero || !ok || value == "" {
+		return nil
+	}
+
+	parsed, err := parse(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	*target = parsed
+
+	return nil
+}
+
+func parseFloat(value string) (float64, error) {
+	return strconv.ParseFloat(value, 64)
+}
+
+func newCacheBackend(kind string, dir string, redisAddr string, limits MemoryCacheLimits) (CacheBackend, error) {
+	switch kind {
+	case "memory":
+		return NewMemoryCache(1*time.Minute, limits), nil
+	case "disk":
+		return NewDiskCache(dir)
+	case "redis":
+		return NewRedisCache(redisAddr)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", kind)
+	}
+}
+
 func main() {
 	var input string
 
 	var conf config
 
-	conf.Redis = new(Caches)
+	cacheKind := flag.String("cache", envOr("POKEDEX_CACHE", "memory"), "Cache backend to use: memory, disk or redis (env POKEDEX_CACHE)")
+	cacheDir := flag.String("cache-dir", envOr("POKEDEX_CACHE_DIR", ""), "Directory for the disk cache (env POKEDEX_CACHE_DIR, defaults to the user cache directory)")
+	redisAddr := flag.String("redis-addr", envOr("POKEDEX_REDIS_ADDR", "localhost:6379"), "Address of the Redis server for the redis cache (env POKEDEX_REDIS_ADDR)")
+	cacheMaxBytes := flag.Int64("cache-max-bytes", 64<<20, "Maximum bytes held by the memory cache (0 for unbounded)")
+	cacheMaxEntries := flag.Int("cache-max-entries", 0, "Maximum entries held by the memory cache (0 for unbounded)")
+	baseURL := flag.String("base-url", envOr("POKEDEX_BASE_URL", ""), "PokeAPI base URL, for self-hosted mirrors (env POKEDEX_BASE_URL, defaults to "+defaultBaseURL+")")
+	retries := flag.Int("retries", 0, "Maximum attempts per request, 0 keeps the configured default (env POKEDEX_RETRIES)")
+	rate := flag.Float64("rate", 0, "Maximum requests per second to PokeAPI, 0 keeps the configured default and a negative value disables limiting (env POKEDEX_RATE)")
+	burst := flag.Int("burst", 0, "Requests allowed in a burst before rate limiting kicks in, 0 keeps the configured default (env POKEDEX_BURST)")
+	timeout := flag.Duration("timeout", 0, "Timeout for each PokeAPI request, 0 keeps the configured default (env POKEDEX_TIMEOUT)")
+	settingsPath := flag.String("config", envOr("POKEDEX_CONFIG", defaultSettingsPath()), "Path to the JSON settings file (env POKEDEX_CONFIG)")
+	staleWhileRevalidate := flag.Bool("stale-while-revalidate", false, "Serve expired cache entries immediately and refresh them in the background")
+
+	flag.Parse()
+
+	err := errors.Join(
+		envDefault(retries, "POKEDEX_RETRIES", strconv.Atoi),
+		envDefault(rate, "POKEDEX_RATE", parseFloat),
+		envDefault(burst, "POKEDEX_BURST", strconv.Atoi),
+		envDefault(timeout, "POKEDEX_TIMEOUT", time.ParseDuration),
+	)
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
 
-	conf.Commands = make(map[string]cliCommand)
+		os.Exit(1)
+	}
+
+	cache, err := newCacheBackend(*cacheKind, *cacheDir, *redisAddr, MemoryCacheLimits{
+		MaxBytes:   *cacheMaxBytes,
+		MaxEntries: *cacheMaxEntries,
+	})
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
+
+		os.Exit(1)
+	}
+
+	conf.Client = NewClient(cache)
+	conf.Client.StaleWhileRevalidate = *staleWhileRevalidate
 
-	conf.Map.Next = "https://pokeapi.co/api/v2/location-area/"
+	settings, err := LoadSettings(*settingsPath)
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
+
+		os.Exit(1)
+	}
+
+	if err := settings.Apply(conf.Client); err != nil {
+		fmt.Println("Error: " + err.Error())
+
+		os.Exit(1)
+	}
+
+	// The environment and flags take precedence over the settings file
+	if *baseURL != "" {
+		conf.Client.BaseURL = *baseURL
+	}
+
+	if *retries > 0 {
+		conf.Client.Retry.MaxAttempts = *retries
+	}
+
+	conf.Client.Limiter = conf.Client.Limiter.WithLimits(*rate, *burst)
+
+	if *timeout > 0 {
+		conf.Client.Timeout = *timeout
+	}
+
+	ttlOverrides, err := ParseTTLSpec(os.Getenv("POKEDEX_TTL"))
+	if err == nil {
+		err = conf.Client.TTL.Apply(ttlOverrides)
+	}
+
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
+
+		os.Exit(1)
+	}
+
+	conf.Map.Next = conf.Client.URL("location-area/")
 	conf.Map.Previous = ""
 
+	conf.Commands = make(map[string]cliCommand)
+
 	conf.Commands["help"] = cliCommand{
 		name:        "help",
 		description: "Displays a help message",
-		callback:    func() error { return commandHelp(&conf) },
+		callback:    func(ctx context.Context) error { return commandHelp(&conf) },
 	}
 
 	conf.Commands["exit"] = cliCommand{
 		name:        "exit",
 		description: "Exit the Pokedex",
-		callback:    func() error { return commandExit(&conf) },
+		callback:    func(ctx context.Context) error { return commandExit(&conf) },
 	}
 
 	conf.Commands["map"] = cliCommand{
 		name:        "map",
 		description: "This will be how we explore the Pokemon world",
-		callback:    func() error { return commandMapForward(&conf) },
+		callback:    func(ctx context.Context) error { return commandMapForward(ctx, &conf) },
 	}
 
 	conf.Commands["mapb"] = cliCommand{
 		name:        "mapb",
 		description: "This will be how we explore the Pokemon world backwards",
-		callback:    func() error { return commandMapBack(&conf) },
+		callback:    func(ctx context.Context) error { return commandMapBack(ctx, &conf) },
 	}
 
 	conf.Commands["explore"] = cliCommand{
 		name:        "explore",
-		description: "Explore the map of a single area",
-		callback: func() error {
-			return commandExploreArea(&conf)
+		description: "Explore the map of a single area, --version=<game> for levels and odds",
+		callback: func(ctx context.Context) error {
+			return commandExploreArea(ctx, &conf)
 		},
 	}
 
 	conf.Commands["catch"] = cliCommand{
 		name:        "catch",
 		description: "Catch a Pokemon",
-		callback: func() error {
-			return commandCatchPokemon(&conf)
+		callback: func(ctx context.Context) error {
+			return commandCatchPokemon(ctx, &conf)
 		},
 	}
 
 	conf.Commands["inspect"] = cliCommand{
 		name:        "inspect",
 		description: "Inspect a Pokemon",
-		callback: func() error {
+		callback: func(ctx context.Context) error {
 			return commandInspectPokemon(&conf)
 		},
 	}
@@ -786,7 +829,7 @@ func main() {
 	conf.Commands["pokedex"] = cliCommand{
 		name:        "pokedex",
 		description: "List all caught Pokemon",
-		callback: func() error {
+		callback: func(ctx context.Context) error {
 			fmt.Println("Your Pokedex:")
 
 			for name := range conf.Pokemon {
@@ -797,6 +840,118 @@ func main() {
 		},
 	}
 
+	conf.Commands["species"] = cliCommand{
+		name:        "species",
+		description: "Show species details: genus, flavor text, capture rate and more",
+		callback: func(ctx context.Context) error {
+			return commandSpecies(ctx, &conf)
+		},
+	}
+
+	conf.Commands["evolution"] = cliCommand{
+		name:        "evolution",
+		description: "Show the evolution tree of a Pokemon",
+		callback: func(ctx context.Context) error {
+			return commandEvolution(ctx, &conf)
+		},
+	}
+
+	conf.Commands["move"] = cliCommand{
+		name:        "move",
+		description: "Show a move's type, power, accuracy, PP, effect and learners",
+		callback: func(ctx context.Context) error {
+			return commandMove(ctx, &conf)
+		},
+	}
+
+	conf.Commands["ability"] = cliCommand{
+		name:        "ability",
+		description: "Show an ability's effect and which Pokemon have it",
+		callback: func(ctx context.Context) error {
+			return commandAbility(ctx, &conf)
+		},
+	}
+
+	conf.Commands["matchup"] = cliCommand{
+		name:        "matchup",
+		description: "Show how effective an attacking type is against a Pokemon or types",
+		callback: func(ctx context.Context) error {
+			return commandMatchup(ctx, &conf)
+		},
+	}
+
+	conf.Commands["item"] = cliCommand{
+		name:        "item",
+		description: "Show an item's cost, category, fling power and effect",
+		callback: func(ctx context.Context) error {
+			return commandItem(ctx, &conf)
+		},
+	}
+
+	conf.Commands["berry"] = cliCommand{
+		name:        "berry",
+		description: "Show a berry's flavors, firmness, growth time and effect",
+		callback: func(ctx context.Context) error {
+			return commandBerry(ctx, &conf)
+		},
+	}
+
+	conf.Commands["regions"] = cliCommand{
+		name:        "regions",
+		description: "List the regions of the Pokemon world",
+		callback: func(ctx context.Context) error {
+			return commandRegions(ctx, &conf)
+		},
+	}
+
+	conf.Commands["region"] = cliCommand{
+		name:        "region",
+		description: "Show a region's locations and scope map to it (region none to reset)",
+		callback: func(ctx context.Context) error {
+			return commandRegion(ctx, &conf)
+		},
+	}
+
+	conf.Commands["location"] = cliCommand{
+		name:        "location",
+		description: "Show the areas of a location",
+		callback: func(ctx context.Context) error {
+			return commandLocation(ctx, &conf)
+		},
+	}
+
+	conf.Commands["where"] = cliCommand{
+		name:        "where",
+		description: "List where a Pokemon can be found in the wild, per game",
+		callback: func(ctx context.Context) error {
+			return commandWhere(ctx, &conf)
+		},
+	}
+
+	conf.Commands["cache"] = cliCommand{
+		name:        "cache",
+		description: "Inspect the cache: cache list|stats|purge <prefix>|refresh <url>",
+		callback: func(ctx context.Context) error {
+			return commandCache(ctx, &conf)
+		},
+	}
+
+	conf.Commands["export"] = cliCommand{
+		name:        "export",
+		description: "Export the cache to a compressed snapshot file",
+		callback: func(ctx context.Context) error {
+			return commandExportCache(&conf)
+		},
+	}
+
+	conf.Commands["import"] = cliCommand{
+		name:        "import",
+		description: "Import a cache snapshot file, skipping expired entries",
+		callback: func(ctx context.Context) error {
+			return commandImportCache(&conf)
+		},
+	}
+
 	scanner := bufio.NewScanner(bufio.NewReader(os.Stdin))
 
 	fmt.Println("Welcome to the Pokedex!")
@@ -804,23 +959,53 @@ func main() {
 	// Our collection of Pokemon
 	conf.Pokemon = make(map[string]Pokemon)
 
+	var runner commandRunner
+
+	interrupts := make(chan os.Signal, 1)
+
+	signal.Notify(interrupts, os.Interrupt)
+
+	// Ctrl-C cancels the running command, or leaves at the prompt
+	go func() {
+		for range interrupts {
+			if !runner.Interrupt() {
+				fmt.Println()
+
+				commandExit(&conf)
+			}
+		}
+	}()
+
 	// REPL loop
 	for {
 		fmt.Print("Pokedex > ")
 
-		scanner.Scan()
+		if !scanner.Scan() {
+			fmt.Println()
+
+			commandExit(&conf)
+		}
 
 		input = scanner.Text()
 
 		clean := cleanInput(input)
 
+		if len(clean) == 0 {
+			continue
+		}
+
 		fmt.Println("Your command was: " + clean[0])
 
 		if command, ok := conf.Commands[clean[0]]; ok {
 			conf.Args = clean[1:]
+			conf.RawArgs = strings.Fields(input)[1:]
+
+			if err := runner.Run(command); err != nil {
+				fmt.Println("Error: " + describeError(err))
+			}
 
-			if err := command.callback(); err != nil {
-				fmt.Println("Error: " + err.Error())
+			if waited := conf.Client.TakeWaited(); waited > 0 {
+				fmt.Println("(Waited " + waited.Round(time.Millisecond).String() + " for the rate limiter)")
 			}
 		} else {
 			fmt.Println("Unknown command")
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type MemoryCacheLimits struct {
+	MaxBytes   int64
+	MaxEntries int
+}
+
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Bytes     int64
+}
+
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries Caches
+	order   *list.List
+	limits  MemoryCacheLimits
+	stats   CacheStats
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// A zero limit means unbounded
+func NewMemoryCache(reapInterval time.Duration, limits MemoryCacheLimits) *MemoryCache {
+	m := &MemoryCache{
+		entries: make(Caches),
+		order:   list.New(),
+		limits:  limits,
+		stop:    make(chan struct{}),
+	}
+
+	if reapInterval > 0 {
+		go m.reap(reapInterval)
+	}
+
+	return m
+}
+
+func (m *MemoryCache) Get(name string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cache, ok := m.entries[name]
+	if !ok || cache.Expired() {
+		m.stats.Misses++
+
+		return nil, false
+	}
+
+	m.stats.Hits++
+
+	m.order.MoveToFront(cache.element)
+
+	return cache.data, true
+}
+
+func (m *MemoryCache) Set(name string, data []byte, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cache, ok := m.entries[name]; ok {
+		m.stats.Bytes += int64(len(data) - len(cache.data))
+
+		cache.expires = time.Now().Add(duration)
+		cache.data = data
+
+		m.order.MoveToFront(cache.element)
+	} else {
+		m.entries[name] = &Cache{
+			expires: time.Now().Add(duration),
+			data:    data,
+			element: m.order.PushFront(name),
+		}
+
+		m.stats.Bytes += int64(len(data))
+	}
+
+	for m.overLimits() {
+		oldest := m.order.Back()
+
+		m.remove(oldest.Value.(string))
+
+		m.stats.Evictions++
+	}
+}
+
+func (m *MemoryCache) Delete(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(name)
+}
+
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.Entries = len(m.entries)
+
+	return stats
+}
+
+func (m *MemoryCache) Range(fn func(name string, data []byte, expires time.Time) bool) {
+	type entry struct {
+		name    string
+		data    []byte
+		expires time.Time
+	}
+
+	m.mu.Lock()
+
+	entries := make([]entry, 0, len(m.entries))
+
+	for name, cache := range m.entries {
+		if !cache.Expired() {
+			entries = append(entries, entry{name, cache.data, cache.expires})
+		}
+	}
+
+	m.mu.Unlock()
+
+	for _, e := range entries {
+		if !fn(e.name, e.data, e.expires) {
+			return
+		}
+	}
+}
+
+// Stop halts the background reaper, it is safe to call more than once
+func (m *MemoryCache) Stop() {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+}
+
+func (m *MemoryCache) overLimits() bool {
+	if len(m.entries) == 0 {
+		return false
+	}
+
+	if m.limits.MaxEntries > 0 && len(m.entries) > m.limits.MaxEntries {
+		return true
+	}
+
+	return m.limits.MaxBytes > 0 && m.stats.Bytes > m.limits.MaxBytes
+}
+
+func (m *MemoryCache) remove(name string) {
+	cache, ok := m.entries[name]
+	if !ok {
+		return
+	}
+
+	m.order.Remove(cache.element)
+
+	m.stats.Bytes -= int64(len(cache.data))
+
+	delete(m.entries, name)
+}
+
+func (m *MemoryCache) reap(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.evictExpired()
+		}
+	}
+}
+
+func (m *MemoryCache) evictExpired() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, cache := range m.entries {
+		if cache.Expired() {
+			m.remove(name)
+		}
+	}
+}
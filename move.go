@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type VerboseEffect struct {
+	Effect      string           `json:"effect"`
+	ShortEffect string           `json:"short_effect"`
+	Language    NamedAPIResource `json:"language"`
+}
+
+type Move struct {
+	ID               int                `json:"id"`
+	Name             string             `json:"name"`
+	Accuracy         *int               `json:"accuracy"`
+	EffectChance     *int               `json:"effect_chance"`
+	PP               *int               `json:"pp"`
+	Priority         int                `json:"priority"`
+	Power            *int               `json:"power"`
+	DamageClass      NamedAPIResource   `json:"damage_class"`
+	EffectEntries    []VerboseEffect    `json:"effect_entries"`
+	Type             NamedAPIResource   `json:"type"`
+	LearnedByPokemon []NamedAPIResource `json:"learned_by_pokemon"`
+}
+
+// Effect returns the English effect text with $effect_chance filled in
+func (m Move) Effect() string {
+	effect, ok := englishEffect(m.EffectEntries)
+	if !ok {
+		return ""
+	}
+
+	text := cleanFlavorText(effect.Effect)
+
+	if m.EffectChance != nil {
+		text = strings.ReplaceAll(text, "$effect_chance", fmt.Sprint(*m.EffectChance))
+	}
+
+	return text
+}
+
+func englishEffect(entries []VerboseEffect) (VerboseEffect, bool) {
+	for _, entry := range entries {
+		if entry.Language.Name == englishLanguage {
+			return entry, true
+		}
+	}
+
+	return VerboseEffect{}, false
+}
+
+// Moves like swords-dance have no power or accuracy
+func formatOptional(value *int) string {
+	if value == nil {
+		return "-"
+	}
+
+	return fmt.Sprint(*value)
+}
+
+// wrapList joins names with commas, breaking lines before width
+func wrapList(names []string, width int, indent string) string {
+	var b strings.Builder
+
+	line := indent
+
+	for i, name := range names {
+		if i < len(names)-1 {
+			name += ","
+		}
+
+		if line != indent && len(line)+1+len(name) > width {
+			b.WriteString(line + "\n")
+
+			line = indent
+		}
+
+		if line != indent {
+			line += " "
+		}
+
+		line += name
+	}
+
+	if line != indent {
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+func commandMove(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: move <name>")
+
+		return nil
+	}
+
+	move, err := Get[Move](ctx, c.Client, c.Client.URL("move", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Name: " + move.Name)
+	fmt.Println("Type: " + move.Type.Name)
+	fmt.Println("Damage class: " + move.DamageClass.Name)
+
+	fmt.Println("Power: " + formatOptional(move.Power))
+	fmt.Println("Accuracy: " + formatOptional(move.Accuracy))
+	fmt.Println("PP: " + formatOptional(move.PP))
+	fmt.Println("Priority: " + fmt.Sprint(move.Priority))
+
+	if effect := move.Effect(); effect != "" {
+		fmt.Println("Effect: " + effect)
+	}
+
+	names := make([]string, 0, len(move.LearnedByPokemon))
+
+	for _, pokemon := range move.LearnedByPokemon {
+		names = append(names, pokemon.Name)
+	}
+
+	fmt.Println("Learned by " + fmt.Sprint(len(names)) + " Pokemon:")
+	fmt.Print(wrapList(names, 80, "  "))
+
+	return nil
+}
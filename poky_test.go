@@ -1,6 +1,20 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -87,3 +101,1805 @@ func TestCache(t *testing.T) {
 		t.Logf("Expected true, got false")
 	}
 }
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	c.Set("https://pokeapi.co/api/v2/pokemon/pikachu", []byte(`{"name":"pikachu"}`), 1*time.Hour)
+	c.Set("expired", []byte(`{"name":"expired"}`), -1*time.Second)
+
+	// A fresh instance on the same directory simulates a restart
+	reopened, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if data, ok := reopened.Get("https://pokeapi.co/api/v2/pokemon/pikachu"); !ok || string(data) != `{"name":"pikachu"}` {
+		t.Errorf("Expected pikachu, got %q (%v)", data, ok)
+	}
+
+	// Bodies are embedded as JSON rather than base64
+	raw, err := os.ReadFile(reopened.path("https://pokeapi.co/api/v2/pokemon/pikachu"))
+	if err != nil || !strings.Contains(string(raw), `"data":{"name":"pikachu"}`) {
+		t.Errorf("Expected the body to be stored as raw JSON, got %s", raw)
+	}
+
+	if _, ok := reopened.Get("expired"); ok {
+		t.Errorf("Expected false, got true")
+	}
+
+	if _, ok := reopened.Get("missing"); ok {
+		t.Errorf("Expected false, got true")
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(100*time.Millisecond, MemoryCacheLimits{})
+	defer c.Stop()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			key := "key" + strconv.Itoa(i%5)
+
+			c.Set(key, []byte(key), 1*time.Hour)
+			c.Get(key)
+		}(i)
+	}
+
+	wg.Wait()
+
+	if c.Len() != 5 {
+		t.Errorf("Expected 5, got %v", c.Len())
+	}
+
+	c.Set("short", []byte("short"), 50*time.Millisecond)
+	c.Set("expired", []byte("expired"), -1*time.Second)
+	c.Set("tiny", []byte("tiny"), 1*time.Microsecond)
+
+	time.Sleep(60 * time.Millisecond)
+
+	// Expired entries must not be served even before the reaper runs
+	if _, ok := c.Get("short"); ok {
+		t.Errorf("Expected false, got true")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if c.Len() != 5 {
+		t.Errorf("Expected 5 after reaping, got %v", c.Len())
+	}
+
+	c.Stop()
+	c.Stop()
+}
+
+func TestMemoryCacheLRU(t *testing.T) {
+	c := NewMemoryCache(0, MemoryCacheLimits{MaxBytes: 10, MaxEntries: 3})
+	defer c.Stop()
+
+	c.Set("a", []byte("aa"), 1*time.Hour)
+	c.Set("b", []byte("bb"), 1*time.Hour)
+	c.Set("c", []byte("cc"), 1*time.Hour)
+
+	// Touch a so b becomes the least recently used entry
+	c.Get("a")
+
+	c.Set("d", []byte("dd"), 1*time.Hour)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Expected %v to be cached", key)
+		}
+	}
+
+	// Pushes the byte budget over 10 and evicts from the tail
+	c.Set("e", []byte("eeeeee"), 1*time.Hour)
+
+	stats := c.Stats()
+
+	if stats.Bytes != 10 {
+		t.Errorf("Expected 10 bytes, got %v", stats.Bytes)
+	}
+
+	if stats.Entries != 3 {
+		t.Errorf("Expected 3 entries, got %v", stats.Entries)
+	}
+
+	if stats.Evictions != 2 {
+		t.Errorf("Expected 2 evictions, got %v", stats.Evictions)
+	}
+
+	if stats.Hits != 4 || stats.Misses != 1 {
+		t.Errorf("Expected 4 hits and 1 miss, got %v and %v", stats.Hits, stats.Misses)
+	}
+}
+
+func expireCached(t *testing.T, client *Client, url string) {
+	data, ok := client.Cache.Get(url)
+	if !ok {
+		t.Fatalf("Expected %v to be cached", url)
+	}
+
+	var entry cachedResponse
+
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entry.Expires = time.Now().Add(-1 * time.Minute)
+
+	encoded, _ := json.Marshal(entry)
+
+	client.Cache.Set(url, encoded, 1*time.Hour)
+}
+
+func TestConditionalRevalidation(t *testing.T) {
+	var full, notModified atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		full.Add(1)
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"name":"pikachu","base_experience":112}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+
+	url := server.URL + "/pokemon/pikachu"
+
+	if _, err := Get[Pokemon](context.Background(), client, url); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expireCached(t, client, url)
+
+	pokemon, err := Get[Pokemon](context.Background(), client, url)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pokemon.Name != "pikachu" {
+		t.Errorf("Expected pikachu, got %v", pokemon.Name)
+	}
+
+	if full.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("Expected 1 full and 1 conditional request, got %v and %v", full.Load(), notModified.Load())
+	}
+
+	// The 304 must have refreshed the TTL
+	if _, err := Get[Pokemon](context.Background(), client, url); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if full.Load()+notModified.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %v", full.Load()+notModified.Load())
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var requests atomic.Int32
+
+	refreshed := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Write([]byte(`{"name":"old"}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"name":"new"}`))
+
+		select {
+		case refreshed <- struct{}{}:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.StaleWhileRevalidate = true
+
+	url := server.URL + "/pokemon/ditto"
+
+	if _, err := Get[Pokemon](context.Background(), client, url); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expireCached(t, client, url)
+
+	pokemon, err := Get[Pokemon](context.Background(), client, url)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pokemon.Name != "old" {
+		t.Errorf("Expected the stale entry, got %v", pokemon.Name)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected a background refresh")
+	}
+
+	// The handler signals before the client stores the body
+	deadline := time.Now().Add(2 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if pokemon, _ = Get[Pokemon](context.Background(), client, url); pokemon.Name == "new" {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if pokemon.Name != "new" {
+		t.Errorf("Expected new, got %v", pokemon.Name)
+	}
+}
+
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string][]byte
+	expires  map[string]time.Time
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	f := &fakeRedis{
+		listener: listener,
+		values:   make(map[string][]byte),
+		expires:  make(map[string]time.Time),
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go f.serve(conn)
+		}
+	}()
+
+	t.Cleanup(func() { listener.Close() })
+
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		request, err := readRESP(reader)
+		if err != nil {
+			return
+		}
+
+		items, _ := request.([]any)
+
+		args := make([]string, 0, len(items))
+
+		for _, item := range items {
+			arg, _ := item.([]byte)
+
+			args = append(args, string(arg))
+		}
+
+		conn.Write([]byte(f.handle(args)))
+	}
+}
+
+func (f *fakeRedis) handle(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(args) == 0 {
+		return "-ERR empty command\r\n"
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, ok := f.values[args[1]]
+
+		if expires, has := f.expires[args[1]]; has && time.Now().After(expires) {
+			ok = false
+		}
+
+		if !ok {
+			return "$-1\r\n"
+		}
+
+		return "$" + strconv.Itoa(len(value)) + "\r\n" + string(value) + "\r\n"
+	case "DEL":
+		_, ok := f.values[args[1]]
+
+		delete(f.values, args[1])
+		delete(f.expires, args[1])
+
+		if ok {
+			return ":1\r\n"
+		}
+
+		return ":0\r\n"
+	case "SET":
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			if ms, _ := strconv.Atoi(args[4]); ms <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+		}
+
+		f.values[args[1]] = []byte(args[2])
+
+		delete(f.expires, args[1])
+
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			ms, _ := strconv.Atoi(args[4])
+
+			f.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+
+		return "+OK\r\n"
+	case "PTTL":
+		if _, ok := f.values[args[1]]; !ok {
+			return ":-2\r\n"
+		}
+
+		expires, ok := f.expires[args[1]]
+		if !ok {
+			return ":-1\r\n"
+		}
+
+		return ":" + strconv.FormatInt(time.Until(expires).Milliseconds(), 10) + "\r\n"
+	case "SCAN":
+		// Everything fits in one page, so the cursor always comes back as 0
+		prefix := strings.TrimSuffix(args[3], "*")
+
+		keys := ""
+		count := 0
+
+		for key := range f.values {
+			if strings.HasPrefix(key, prefix) {
+				keys += "$" + strconv.Itoa(len(key)) + "\r\n" + key + "\r\n"
+				count++
+			}
+		}
+
+		return "*2\r\n$1\r\n0\r\n*" + strconv.Itoa(count) + "\r\n" + keys
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	// Point POKEDEX_TEST_REDIS_ADDR at a real redis-server to test against it
+	addr := os.Getenv("POKEDEX_TEST_REDIS_ADDR")
+
+	if addr == "" {
+		addr = newFakeRedis(t).listener.Addr().String()
+	}
+
+	c, err := NewRedisCache(addr)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer c.Close()
+
+	payload := []byte("{\"name\":\"pikachu\"}\r\nbinary\x00safe")
+
+	c.Set("https://pokeapi.co/api/v2/pokemon/pikachu", payload, 1*time.Hour)
+	c.Set("short", []byte("short"), 50*time.Millisecond)
+	c.Set("expired", []byte("expired"), -1*time.Second)
+	c.Set("tiny", []byte("tiny"), 1*time.Microsecond)
+
+	if data, ok := c.Get("https://pokeapi.co/api/v2/pokemon/pikachu"); !ok || string(data) != string(payload) {
+		t.Errorf("Expected %q, got %q (%v)", payload, data, ok)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, ok := c.Get("short"); ok {
+		t.Errorf("Expected false, got true")
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Errorf("Expected false, got true")
+	}
+
+	// Expired writes must not persist, and sub-millisecond ones are clamped
+	if _, ok := c.Get("expired"); ok {
+		t.Errorf("Expected an expired Set not to be stored")
+	}
+
+	if _, ok := c.Get("tiny"); ok {
+		t.Errorf("Expected a 1µs entry to have expired")
+	}
+
+	var ranged []string
+
+	c.Range(func(name string, data []byte, expires time.Time) bool {
+		ranged = append(ranged, name)
+
+		return true
+	})
+
+	if len(ranged) != 1 || ranged[0] != "https://pokeapi.co/api/v2/pokemon/pikachu" {
+		t.Errorf("Expected only the pikachu key, got %v", ranged)
+	}
+
+	// A dropped connection is re-established on the next command
+	c.Close()
+
+	if _, ok := c.Get("https://pokeapi.co/api/v2/pokemon/pikachu"); !ok {
+		t.Errorf("Expected true after reconnecting, got false")
+	}
+}
+
+func TestSingleFlightFetch(t *testing.T) {
+	var requests atomic.Int32
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		<-release
+
+		w.Write([]byte(`{"name":"snorlax","base_experience":189}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+
+	url := server.URL + "/pokemon/snorlax"
+
+	var wg sync.WaitGroup
+
+	results := make(chan Pokemon, 10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			pokemon, err := Get[Pokemon](context.Background(), client, url)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+
+			results <- pokemon
+		}()
+	}
+
+	// Give every caller a chance to join the in-flight request
+	time.Sleep(100 * time.Millisecond)
+
+	close(release)
+
+	wg.Wait()
+	close(results)
+
+	for pokemon := range results {
+		if pokemon.Name != "snorlax" {
+			t.Errorf("Expected snorlax, got %v", pokemon.Name)
+		}
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %v", requests.Load())
+	}
+}
+
+func TestSingleFlightCancelledLeader(t *testing.T) {
+	var requests atomic.Int32
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		<-release
+
+		w.Write([]byte(`{"name":"snorlax","base_experience":189}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+
+	leader := make(chan error, 1)
+
+	go func() {
+		_, err := Get[Pokemon](leaderCtx, client, "pokemon/snorlax")
+
+		leader <- err
+	}()
+
+	// Let the leader start the request before the waiter joins
+	time.Sleep(50 * time.Millisecond)
+
+	waiter := make(chan error, 1)
+
+	go func() {
+		pokemon, err := Get[Pokemon](context.Background(), client, "pokemon/snorlax")
+		if err == nil && pokemon.Name != "snorlax" {
+			err = errors.New("got " + pokemon.Name)
+		}
+
+		waiter <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the leader to be cancelled, got %v", err)
+	}
+
+	close(release)
+
+	if err := <-waiter; err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %v", requests.Load())
+	}
+}
+
+func TestNegativeCaching(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+
+	url := client.URL("pokemon", "pikachuu")
+
+	for i := 0; i < 3; i++ {
+		_, err := Get[Pokemon](context.Background(), client, url)
+
+		var notFound *NotFoundError
+
+		if !errors.As(err, &notFound) {
+			t.Fatalf("Expected a NotFoundError, got %v", err)
+		}
+
+		if err.Error() != "unknown pokemon: pikachuu" {
+			t.Errorf("Expected unknown pokemon: pikachuu, got %v", err)
+		}
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %v", requests.Load())
+	}
+}
+
+func TestTTLPolicy(t *testing.T) {
+	policy := DefaultTTLPolicy()
+
+	overrides, err := ParseTTLSpec("pokemon=2h, list=10m,move=never,not-found=30s")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := policy.Apply(overrides); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cases := []struct {
+		url      string
+		expected time.Duration
+	}{
+		{"https://pokeapi.co/api/v2/pokemon/pikachu", 2 * time.Hour},
+		{"https://pokeapi.co/api/v2/pokemon/25/", 2 * time.Hour},
+		{"https://pokeapi.co/api/v2/location-area/", 10 * time.Minute},
+		{"https://pokeapi.co/api/v2/location-area/?offset=20&limit=20", 10 * time.Minute},
+		{"https://pokeapi.co/api/v2/location-area/canalave-city-area", NeverExpire},
+		{"https://pokeapi.co/api/v2/move/tackle", NeverExpire},
+		{"https://pokeapi.co/api/v2/berry/cheri", 24 * time.Hour},
+	}
+
+	for _, c := range cases {
+		if actual := policy.For(c.url); actual != c.expected {
+			t.Errorf("Expected %v for %v, got %v", c.expected, c.url, actual)
+		}
+	}
+
+	if policy.ForNotFound() != 30*time.Second {
+		t.Errorf("Expected 30s, got %v", policy.ForNotFound())
+	}
+
+	if _, err := ParseTTLSpec("pokemon"); err == nil {
+		t.Errorf("Expected an error for a missing duration")
+	}
+
+	if err := policy.Apply(map[string]string{"pokemon": "soon"}); err == nil {
+		t.Errorf("Expected an error for an invalid duration")
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	source := NewMemoryCache(0, MemoryCacheLimits{})
+	defer source.Stop()
+
+	source.Set("https://pokeapi.co/api/v2/pokemon/eevee", []byte(`{"name":"eevee"}`), 1*time.Hour)
+	source.Set("https://pokeapi.co/api/v2/pokemon/mew", []byte(`{"name":"mew"}`), 1*time.Hour)
+
+	var buf bytes.Buffer
+
+	count, err := ExportSnapshot(&buf, source)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2, got %v", count)
+	}
+
+	target, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	imported, skipped, err := ImportSnapshot(&buf, target)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if imported != 2 || skipped != 0 {
+		t.Errorf("Expected 2 imported and 0 skipped, got %v and %v", imported, skipped)
+	}
+
+	if data, ok := target.Get("https://pokeapi.co/api/v2/pokemon/mew"); !ok || string(data) != `{"name":"mew"}` {
+		t.Errorf("Expected mew, got %q (%v)", data, ok)
+	}
+
+	// Hand-built snapshot with an expired and a keyless entry
+	buf.Reset()
+
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+
+	encoder.Encode(snapshotHeader{Version: snapshotVersion, Created: time.Now()})
+	encoder.Encode(snapshotEntry{Key: "expired", Expires: time.Now().Add(-1 * time.Hour), Data: []byte(`{}`)})
+	encoder.Encode(snapshotEntry{Key: "", Expires: time.Now().Add(1 * time.Hour), Data: []byte(`{}`)})
+	encoder.Encode(snapshotEntry{Key: "valid", Expires: time.Now().Add(1 * time.Hour), Data: []byte(`{}`)})
+
+	zw.Close()
+
+	imported, skipped, err = ImportSnapshot(&buf, target)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if imported != 1 || skipped != 2 {
+		t.Errorf("Expected 1 imported and 2 skipped, got %v and %v", imported, skipped)
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	cache := NewMemoryCache(0, MemoryCacheLimits{})
+	defer cache.Stop()
+
+	cache.Set("https://pokeapi.co/api/v2/location-area/", []byte(`{}`), 1*time.Hour)
+	cache.Set("https://pokeapi.co/api/v2/location-area/?offset=20&limit=20", []byte(`{}`), 1*time.Hour)
+	cache.Set("https://pokeapi.co/api/v2/pokemon/pikachu", []byte(`{}`), 1*time.Hour)
+
+	conf := &config{Client: NewClient(cache), RawArgs: []string{"purge", "location-area"}}
+
+	if err := commandCache(context.Background(), conf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cache.Len() != 1 {
+		t.Errorf("Expected 1, got %v", cache.Len())
+	}
+
+	if _, ok := cache.Get("https://pokeapi.co/api/v2/pokemon/pikachu"); !ok {
+		t.Errorf("Expected pikachu to survive the purge")
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	var down atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte(`{"id":25,"name":"pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+
+	conf := &config{Client: client, RawArgs: []string{"refresh", "pokemon/pikachu"}}
+
+	if _, err := client.Fetch(context.Background(), "pokemon/pikachu"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	down.Store(true)
+
+	if err := commandCache(context.Background(), conf); err == nil {
+		t.Errorf("Expected an error while the server is down")
+	}
+
+	data, err := client.Fetch(context.Background(), "pokemon/pikachu")
+	if err != nil {
+		t.Fatalf("Expected the cached entry to survive, got %v", err)
+	}
+
+	if !strings.Contains(string(data), "pikachu") {
+		t.Errorf("Expected pikachu, got %v", string(data))
+	}
+}
+
+func TestClientGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/location-area/":
+			w.Write([]byte(`{"count":1,"next":null,"results":[{"name":"canalave-city-area","url":"x"}]}`))
+		case "/api/v2/location-area/canalave-city-area":
+			w.Write([]byte(`{"id":1,"name":"canalave-city-area"}`))
+		default:
+			http.Error(w, "<html>oops</html>", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+
+	list, err := Get[NamedAPIResourceList](context.Background(), client, server.URL+"/api/v2/location-area/")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(list.Results) != 1 || list.Results[0].Name != "canalave-city-area" {
+		t.Errorf("Expected canalave-city-area, got %v", list.Results)
+	}
+
+	area, err := Get[LocationArea](context.Background(), client, server.URL+"/api/v2/location-area/canalave-city-area")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if area.ID != 1 || area.Name != "canalave-city-area" {
+		t.Errorf("Expected canalave-city-area, got %v", area.Name)
+	}
+
+	if data, _ := client.Cache.Get(server.URL + "/api/v2/location-area/canalave-city-area"); !strings.Contains(string(data), `"body":{"id":1`) {
+		t.Errorf("Expected the body to be cached as raw JSON, got %s", data)
+	}
+
+	if _, err := Get[Pokemon](context.Background(), client, server.URL+"/api/v2/pokemon/broken"); err == nil {
+		t.Errorf("Expected an error for a 500 response")
+	}
+
+	if _, ok := client.Cache.Get(server.URL + "/api/v2/pokemon/broken"); ok {
+		t.Errorf("Expected failed responses not to be cached")
+	}
+}
+
+func TestUndecodableResponseNotCached(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Write([]byte(`<html>maintenance</html>`))
+
+			return
+		}
+
+		w.Write([]byte(`{"id":25,"name":"pikachu"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+
+	var decodeErr *DecodeError
+
+	if _, err := Get[Pokemon](context.Background(), client, "pokemon/pikachu"); !errors.As(err, &decodeErr) {
+		t.Errorf("Expected a DecodeError, got %v", err)
+	}
+
+	pokemon, err := Get[Pokemon](context.Background(), client, "pokemon/pikachu")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pokemon.Name != "pikachu" {
+		t.Errorf("Expected pikachu, got %v", pokemon.Name)
+	}
+
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2, got %v", requests.Load())
+	}
+}
+
+func TestEnvDefault(t *testing.T) {
+	t.Setenv("POKEDEX_BURST", "20")
+	t.Setenv("POKEDEX_TIMEOUT", "soon")
+	t.Setenv("POKEDEX_RATE", "")
+
+	burst := 0
+
+	if err := envDefault(&burst, "POKEDEX_BURST", strconv.Atoi); err != nil || burst != 20 {
+		t.Errorf("Expected 20, got %v (%v)", burst, err)
+	}
+
+	// A flag that was set wins over the environment
+	burst = 5
+
+	if err := envDefault(&burst, "POKEDEX_BURST", strconv.Atoi); err != nil || burst != 5 {
+		t.Errorf("Expected 5, got %v (%v)", burst, err)
+	}
+
+	var timeout time.Duration
+
+	if err := envDefault(&timeout, "POKEDEX_TIMEOUT", time.ParseDuration); err == nil || !strings.Contains(err.Error(), "POKEDEX_TIMEOUT") {
+		t.Errorf("Expected a POKEDEX_TIMEOUT error, got %v", err)
+	}
+
+	rate := 0.0
+
+	if err := envDefault(&rate, "POKEDEX_RATE", parseFloat); err != nil || rate != 0 {
+		t.Errorf("Expected an unset variable to be ignored, got %v (%v)", rate, err)
+	}
+}
+
+func TestClientResolve(t *testing.T) {
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = "http://localhost:8000/api/v2"
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"pokemon/pikachu", "http://localhost:8000/api/v2/pokemon/pikachu"},
+		{"https://pokeapi.co/api/v2/location-area/?offset=20&limit=20", "http://localhost:8000/api/v2/location-area/?offset=20&limit=20"},
+		{"http://localhost:8000/api/v2/pokemon/25/", "http://localhost:8000/api/v2/pokemon/25/"},
+		{"https://example.com/elsewhere", "https://example.com/elsewhere"},
+		{"https://pokeapi.co/api/v2/pokemon/50%25", "http://localhost:8000/api/v2/pokemon/50%25"},
+	}
+
+	for _, c := range cases {
+		if actual := client.Resolve(c.input); actual != c.expected {
+			t.Errorf("Expected %v, got %v", c.expected, actual)
+		}
+	}
+
+	if actual := client.URL("location-area", "canalave-city-area"); actual != "http://localhost:8000/api/v2/location-area/canalave-city-area" {
+		t.Errorf("Expected the mirror URL, got %v", actual)
+	}
+
+	urls := []struct {
+		parts    []string
+		expected string
+	}{
+		{[]string{"location-area/"}, "http://localhost:8000/api/v2/location-area/"},
+		{[]string{"pokemon", "50%"}, "http://localhost:8000/api/v2/pokemon/50%25"},
+		{[]string{"pokemon", "pikachu?x=1"}, "http://localhost:8000/api/v2/pokemon/pikachu%3Fx=1"},
+		{[]string{"pokemon", "../berry/cheri"}, "http://localhost:8000/api/v2/pokemon/..%2Fberry%2Fcheri"},
+	}
+
+	for _, u := range urls {
+		if actual := client.URL(u.parts...); actual != u.expected {
+			t.Errorf("Expected %v, got %v", u.expected, actual)
+		}
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/pokemon/limited":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/api/v2/pokemon/broken":
+			http.Error(w, "<html>oops</html>", http.StatusBadGateway)
+		default:
+			w.Write([]byte("<html>not json</html>"))
+		}
+	}))
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+
+	_, err := Get[Pokemon](context.Background(), client, client.URL("pokemon", "limited"))
+
+	var rateLimited *RateLimitedError
+
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter != 30*time.Second {
+		t.Errorf("Expected a RateLimitedError with a 30s Retry-After, got %v", err)
+	}
+
+	_, err = Get[Pokemon](context.Background(), client, client.URL("pokemon", "broken"))
+
+	var serverErr *ServerError
+
+	if !errors.As(err, &serverErr) || serverErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected a ServerError with status 502, got %v", err)
+	}
+
+	_, err = Get[Pokemon](context.Background(), client, client.URL("pokemon", "html"))
+
+	var decode *DecodeError
+
+	if !errors.As(err, &decode) {
+		t.Errorf("Expected a DecodeError, got %v", err)
+	}
+
+	server.Close()
+
+	_, err = Get[Pokemon](context.Background(), client, client.URL("pokemon", "offline"))
+
+	var network *NetworkError
+
+	if !errors.As(err, &network) {
+		t.Errorf("Expected a NetworkError, got %v", err)
+	}
+
+	_, err = Get[Pokemon](context.Background(), client, "http://localhost/api/v2/pokemon/50%")
+
+	var request *RequestError
+
+	if !errors.As(err, &request) || describeError(err) != "Invalid URL: http://localhost/api/v2/pokemon/50%" {
+		t.Errorf("Expected a RequestError, got %v", err)
+	}
+
+	if message := describeError(&NotFoundError{Resource: "pokemon", Name: "pikachuu"}); message != "Unknown pokemon: pikachuu" {
+		t.Errorf("Expected Unknown pokemon: pikachuu, got %v", message)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"name":"magikarp","base_experience":40}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: 1 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+	start := time.Now()
+
+	pokemon, err := Get[Pokemon](context.Background(), client, client.URL("pokemon", "magikarp"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pokemon.Name != "magikarp" {
+		t.Errorf("Expected magikarp, got %v", pokemon.Name)
+	}
+
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %v", requests.Load())
+	}
+
+	// Retry-After overrides the much shorter backoff
+	if elapsed := time.Since(start); elapsed < 1*time.Second {
+		t.Errorf("Expected to wait at least 1s, waited %v", elapsed)
+	}
+
+	// A 503 can ask for a delay too
+	var unavailable atomic.Int32
+
+	maintenance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unavailable.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte(`{"name":"magikarp","base_experience":40}`))
+	}))
+	defer maintenance.Close()
+
+	client.BaseURL = maintenance.URL + "/api/v2/"
+
+	start = time.Now()
+
+	if _, err := Get[Pokemon](context.Background(), client, client.URL("pokemon", "magikarp")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if unavailable.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %v", unavailable.Load())
+	}
+
+	if elapsed := time.Since(start); elapsed < 1*time.Second {
+		t.Errorf("Expected to wait at least 1s, waited %v", elapsed)
+	}
+
+	// A Retry-After beyond MaxDelay gives up straight away
+	var limited atomic.Int32
+
+	rateLimited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limited.Add(1)
+
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer rateLimited.Close()
+
+	client.BaseURL = rateLimited.URL + "/api/v2/"
+
+	var rateLimitedErr *RateLimitedError
+
+	start = time.Now()
+
+	if _, err := Get[Pokemon](context.Background(), client, client.URL("pokemon", "magikarp")); !errors.As(err, &rateLimitedErr) {
+		t.Errorf("Expected a RateLimitedError, got %v", err)
+	}
+
+	if limited.Load() != 1 {
+		t.Errorf("Expected 1 request, got %v", limited.Load())
+	}
+
+	if elapsed := time.Since(start); elapsed > 1*time.Second {
+		t.Errorf("Expected to give up immediately, waited %v", elapsed)
+	}
+
+	if delay := client.Retry.Delay(1, &RateLimitedError{RetryAfter: time.Hour}); delay != client.Retry.MaxDelay {
+		t.Errorf("Expected %v, got %v", client.Retry.MaxDelay, delay)
+	}
+
+	// Client errors are not retried
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	client.BaseURL = notFound.URL + "/api/v2/"
+
+	if _, err := Get[Pokemon](context.Background(), client, client.URL("pokemon", "missingno")); err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	for retry, max := range []time.Duration{100, 200, 300, 300} {
+		delay := policy.Delay(retry+1, &ServerError{StatusCode: 500})
+
+		if delay < max*time.Millisecond/2 || delay > max*time.Millisecond {
+			t.Errorf("Expected retry %v to wait between %v and %v, got %v", retry+1, max*time.Millisecond/2, max*time.Millisecond, delay)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(20, 2)
+
+	// The burst is free, after that requests are spaced 50ms apart
+	for i := 0; i < 2; i++ {
+		if wait := limiter.Reserve(); wait != 0 {
+			t.Errorf("Expected no wait within the burst, got %v", wait)
+		}
+	}
+
+	if wait := limiter.Reserve(); wait < 40*time.Millisecond || wait > 50*time.Millisecond {
+		t.Errorf("Expected about 50ms, got %v", wait)
+	}
+
+	if wait := limiter.Reserve(); wait < 90*time.Millisecond || wait > 100*time.Millisecond {
+		t.Errorf("Expected about 100ms, got %v", wait)
+	}
+
+	// Cancelled waits give their tokens back
+	limiter = NewRateLimiter(10, 1)
+	limiter.Reserve()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 30; i++ {
+		if _, err := limiter.Wait(cancelled); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected a cancelled wait, got %v", err)
+		}
+	}
+
+	if wait := limiter.Reserve(); wait > 100*time.Millisecond {
+		t.Errorf("Expected at most 100ms, got %v", wait)
+	}
+
+	var disabled *RateLimiter
+
+	if wait, _ := disabled.Wait(context.Background()); wait != 0 {
+		t.Errorf("Expected a nil limiter not to wait, got %v", wait)
+	}
+
+	if NewRateLimiter(5, 10).WithLimits(-1, 0) != nil {
+		t.Errorf("Expected a negative rate to disable limiting")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Limiter = NewRateLimiter(20, 1)
+
+	for _, name := range []string{"bulbasaur", "ivysaur", "venusaur"} {
+		if _, err := Get[Pokemon](context.Background(), client, client.URL("pokemon", name)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if waited := client.TakeWaited(); waited < 80*time.Millisecond {
+		t.Errorf("Expected to wait about 100ms, waited %v", waited)
+	}
+
+	if waited := client.TakeWaited(); waited != 0 {
+		t.Errorf("Expected the wait to be reset, got %v", waited)
+	}
+
+	// Parallel fetches wait side by side, so five queued requests at 10/s
+	// hold the command up for 400ms rather than 0+100+200+300+400ms
+	limiter = NewRateLimiter(10, 1)
+
+	for i := 0; i < 5; i++ {
+		limiter.Reserve()
+	}
+
+	if waited := limiter.TakeWaited(); waited < 390*time.Millisecond || waited > 410*time.Millisecond {
+		t.Errorf("Expected to wait about 400ms, waited %v", waited)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+	client.Timeout = 50 * time.Millisecond
+
+	_, err := Get[Pokemon](context.Background(), client, client.URL("pokemon", "slowpoke"))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+
+	client.Timeout = 0
+
+	var runner commandRunner
+
+	result := make(chan error, 1)
+
+	go func() {
+		result <- runner.Run(cliCommand{
+			callback: func(ctx context.Context) error {
+				_, err := Get[Pokemon](ctx, client, client.URL("pokemon", "slowbro"))
+
+				return err
+			},
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if !runner.Interrupt() {
+		t.Fatalf("Expected a running command to interrupt")
+	}
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) || describeError(err) != "Cancelled" {
+			t.Errorf("Expected a cancelled error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected the command to stop after an interrupt")
+	}
+
+	if runner.Interrupt() {
+		t.Errorf("Expected nothing to interrupt at the prompt")
+	}
+}
+
+func TestRenderEvolutionTree(t *testing.T) {
+	var chain EvolutionChain
+
+	err := json.Unmarshal([]byte(`{"chain":{
+		"species":{"name":"eevee"},
+		"evolves_to":[
+			{"species":{"name":"vaporeon"},"evolution_details":[{"trigger":{"name":"use-item"},"item":{"name":"water-stone"}}],"evolves_to":[]},
+			{"species":{"name":"espeon"},"evolution_details":[{"trigger":{"name":"level-up"},"min_happiness":160,"time_of_day":"day"}],"evolves_to":[]},
+			{"species":{"name":"leafeon"},"evolution_details":[
+				{"trigger":{"name":"level-up"},"location":{"name":"eterna-forest"}},
+				{"trigger":{"name":"use-item"},"item":{"name":"leaf-stone"}}
+			],"evolves_to":[]}
+		]
+	}}`), &chain)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "eevee\n" +
+		"├── vaporeon (use-item: with water-stone)\n" +
+		"├── espeon (level-up: friendship 160+, during the day)\n" +
+		"└── leafeon (level-up: at eterna-forest or use-item: with leaf-stone)\n"
+
+	if actual := renderEvolutionTree(chain.Chain); actual != expected {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, actual)
+	}
+}
+
+func TestMoveEffect(t *testing.T) {
+	var move Move
+
+	err := json.Unmarshal([]byte(`{
+		"name":"thunderbolt",
+		"effect_chance":10,
+		"effect_entries":[
+			{"effect":"Inflicts regular damage.  Has a $effect_chance%\nchance to paralyze the target.","short_effect":"","language":{"name":"en"}}
+		]
+	}`), &move)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "Inflicts regular damage. Has a 10% chance to paralyze the target."
+
+	if actual := move.Effect(); actual != expected {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	if formatOptional(move.Power) != "-" {
+		t.Errorf("Expected -, got %v", formatOptional(move.Power))
+	}
+
+	wrapped := wrapList([]string{"pikachu", "raichu", "zapdos"}, 20, "  ")
+
+	if wrapped != "  pikachu, raichu,\n  zapdos\n" {
+		t.Errorf("Expected two lines, got %q", wrapped)
+	}
+}
+
+func TestTypeMultiplier(t *testing.T) {
+	var electric Type
+
+	err := json.Unmarshal([]byte(`{"name":"electric","damage_relations":{
+		"double_damage_to":[{"name":"flying"},{"name":"water"}],
+		"half_damage_to":[{"name":"dragon"},{"name":"electric"},{"name":"grass"}],
+		"no_damage_to":[{"name":"ground"}]
+	}}`), &electric)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cases := []struct {
+		defending []string
+		expected  string
+	}{
+		{[]string{"water", "flying"}, "4x"},
+		{[]string{"water"}, "2x"},
+		{[]string{"normal"}, "1x"},
+		{[]string{"water", "grass"}, "1x"},
+		{[]string{"grass", "dragon"}, "0.25x"},
+		{[]string{"water", "ground"}, "0x"},
+	}
+
+	for _, c := range cases {
+		if actual := formatMultiplier(electric.CombinedMultiplier(c.defending)); actual != c.expected {
+			t.Errorf("Expected %v against %v, got %v", c.expected, c.defending, actual)
+		}
+	}
+}
+
+func TestRegionScopedMap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v2/region/sinnoh":
+			locations := make([]NamedAPIResource, 0)
+
+			for i := 0; i < 25; i++ {
+				name := "route-" + strconv.Itoa(201+i)
+
+				locations = append(locations, NamedAPIResource{Name: name, URL: "https://pokeapi.co/api/v2/location/" + name})
+			}
+
+			json.NewEncoder(w).Encode(Region{Name: "sinnoh", Locations: locations})
+		case strings.HasPrefix(r.URL.Path, "/api/v2/location/"):
+			name := strings.TrimPrefix(r.URL.Path, "/api/v2/location/")
+
+			json.NewEncoder(w).Encode(Location{Name: name, Areas: []NamedAPIResource{{Name: name + "-area"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Limiter = nil
+
+	conf := &config{Client: client, Args: []string{"sinnoh"}}
+
+	if err := commandRegion(context.Background(), conf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if conf.Map.Region != "sinnoh" {
+		t.Errorf("Expected sinnoh, got %v", conf.Map.Region)
+	}
+
+	steps := []struct {
+		command  func(context.Context, *config) error
+		expected int
+	}{
+		{commandMapForward, 20},
+		{commandMapForward, 40},
+		{commandMapForward, 40},
+		{commandMapBack, 20},
+		{commandMapBack, 20},
+	}
+
+	for i, step := range steps {
+		if err := step.command(context.Background(), conf); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if conf.Map.Offset != step.expected {
+			t.Errorf("Expected offset %v after step %v, got %v", step.expected, i+1, conf.Map.Offset)
+		}
+	}
+}
+
+func TestGroupEncounters(t *testing.T) {
+	var encounters []LocationAreaEncounter
+
+	err := json.Unmarshal([]byte(`[
+		{"location_area":{"name":"viridian-forest-area"},"version_details":[
+			{"version":{"name":"red"},"max_chance":10,"encounter_details":[
+				{"chance":5,"min_level":3,"max_level":3,"method":{"name":"walk"}},
+				{"chance":5,"min_level":5,"max_level":5,"method":{"name":"walk"}}
+			]},
+			{"version":{"name":"blue"},"max_chance":5,"encounter_details":[
+				{"chance":5,"min_level":3,"max_level":5,"method":{"name":"walk"}}
+			]}
+		]},
+		{"location_area":{"name":"power-plant-area"},"version_details":[
+			{"version":{"name":"red"},"max_chance":25,"encounter_details":[
+				{"chance":25,"min_level":21,"max_level":24,"method":{"name":"walk"}}
+			]}
+		]}
+	]`), &encounters)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	grouped := groupEncounters(encounters)
+
+	if len(grouped) != 2 || grouped[0].Version != "blue" || grouped[1].Version != "red" {
+		t.Fatalf("Expected blue then red, got %v", grouped)
+	}
+
+	red := grouped[1].Rows
+
+	expected := []encounterRow{
+		{Area: "power-plant-area", Method: "walk", MinLevel: 21, MaxLevel: 24, Chance: 25},
+		{Area: "viridian-forest-area", Method: "walk", MinLevel: 3, MaxLevel: 5, Chance: 10},
+	}
+
+	if len(red) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, red)
+	}
+
+	for i := range expected {
+		if red[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], red[i])
+		}
+	}
+
+	// Mutually exclusive conditions stay on separate rows instead of summing
+	err = json.Unmarshal([]byte(`[
+		{"location_area":{"name":"route-201-area"},"version_details":[
+			{"version":{"name":"diamond"},"max_chance":10,"encounter_details":[
+				{"chance":10,"min_level":2,"max_level":2,"method":{"name":"walk"},"condition_values":[{"name":"time-morning"},{"name":"radar-off"}]},
+				{"chance":10,"min_level":3,"max_level":3,"method":{"name":"walk"},"condition_values":[{"name":"radar-off"},{"name":"time-morning"}]},
+				{"chance":10,"min_level":2,"max_level":2,"method":{"name":"walk"},"condition_values":[{"name":"time-night"},{"name":"radar-off"}]},
+				{"chance":10,"min_level":2,"max_level":2,"method":{"name":"walk"},"condition_values":[{"name":"time-morning"},{"name":"radar-on"}]},
+				{"chance":10,"min_level":2,"max_level":2,"method":{"name":"walk"},"condition_values":[{"name":"time-night"},{"name":"radar-on"}]}
+			]}
+		]}
+	]`), &encounters)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	grouped = groupEncounters(encounters)
+
+	if len(grouped) != 1 {
+		t.Fatalf("Expected diamond, got %v", grouped)
+	}
+
+	expected = []encounterRow{
+		{Area: "route-201-area", Method: "walk", MinLevel: 2, MaxLevel: 3, Chance: 20, Conditions: "radar-off, time-morning"},
+		{Area: "route-201-area", Method: "walk", MinLevel: 2, MaxLevel: 2, Chance: 10, Conditions: "radar-off, time-night"},
+		{Area: "route-201-area", Method: "walk", MinLevel: 2, MaxLevel: 2, Chance: 10, Conditions: "radar-on, time-morning"},
+		{Area: "route-201-area", Method: "walk", MinLevel: 2, MaxLevel: 2, Chance: 10, Conditions: "radar-on, time-night"},
+	}
+
+	diamond := grouped[0].Rows
+
+	if len(diamond) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, diamond)
+	}
+
+	for i := range expected {
+		if diamond[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], diamond[i])
+		}
+	}
+}
+
+func TestEncounterTable(t *testing.T) {
+	var area LocationArea
+
+	err := json.Unmarshal([]byte(`{
+		"name":"canalave-city-area",
+		"encounter_method_rates":[
+			{"encounter_method":{"name":"surf"},"version_details":[{"rate":10,"version":{"name":"diamond"}}]}
+		],
+		"pokemon_encounters":[
+			{"pokemon":{"name":"tentacool"},"version_details":[
+				{"version":{"name":"diamond"},"max_chance":60,"encounter_details":[
+					{"chance":60,"min_level":20,"max_level":30,"method":{"name":"surf"},"condition_values":[]}
+				]},
+				{"version":{"name":"pearl"},"max_chance":60,"encounter_details":[
+					{"chance":60,"min_level":20,"max_level":30,"method":{"name":"surf"},"condition_values":[]}
+				]}
+			]},
+			{"pokemon":{"name":"magikarp"},"version_details":[
+				{"version":{"name":"diamond"},"max_chance":100,"encounter_details":[
+					{"chance":60,"min_level":3,"max_level":10,"method":{"name":"old-rod"},"condition_values":[]},
+					{"chance":40,"min_level":10,"max_level":15,"method":{"name":"old-rod"},"condition_values":[]}
+				]}
+			]},
+			{"pokemon":{"name":"wingull"},"version_details":[
+				{"version":{"name":"diamond"},"max_chance":30,"encounter_details":[
+					{"chance":30,"min_level":20,"max_level":30,"method":{"name":"surf"},"condition_values":[{"name":"time-day"}]}
+				]}
+			]}
+		]
+	}`), &area)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	table := encounterTable(area, "diamond")
+
+	if len(table) != 2 || table[0].Method != "old-rod" || table[1].Method != "surf" {
+		t.Fatalf("Expected old-rod then surf, got %v", table)
+	}
+
+	if table[1].Rate != 10 {
+		t.Errorf("Expected a surf rate of 10, got %v", table[1].Rate)
+	}
+
+	magikarp := exploreRow{Pokemon: "magikarp", MinLevel: 3, MaxLevel: 15, Chance: 100}
+
+	if len(table[0].Rows) != 1 || table[0].Rows[0] != magikarp {
+		t.Errorf("Expected %v, got %v", magikarp, table[0].Rows)
+	}
+
+	if len(table[1].Rows) != 2 || table[1].Rows[1].Conditions != "time-day" {
+		t.Errorf("Expected wingull to keep its condition, got %v", table[1].Rows)
+	}
+
+	if len(encounterTable(area, "platinum")) != 0 {
+		t.Errorf("Expected no encounters in platinum")
+	}
+
+	if area, version := parseExploreArgs([]string{"--version", "diamond", "canalave-city-area"}); area != "canalave-city-area" || version != "diamond" {
+		t.Errorf("Expected canalave-city-area in diamond, got %v in %v", area, version)
+	}
+}
+
+// captureOutput returns everything fn prints to stdout
+func captureOutput(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	output := make(chan string)
+
+	go func() {
+		var buf bytes.Buffer
+
+		buf.ReadFrom(reader)
+		output <- buf.String()
+	}()
+
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	fn()
+
+	writer.Close()
+
+	return <-output
+}
+
+// newFixtureClient serves each path under /api/v2/ from a canned JSON body
+func newFixtureClient(t *testing.T, fixtures map[string]string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := fixtures[strings.TrimPrefix(r.URL.Path, "/api/v2/")]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(NewMemoryCache(0, MemoryCacheLimits{}))
+	client.BaseURL = server.URL + "/api/v2/"
+	client.Retry = RetryPolicy{MaxAttempts: 1}
+	client.Limiter = nil
+
+	return client
+}
+
+func TestSpecies(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{
+		"pokemon-species/mew": `{
+			"id": 151,
+			"name": "mew",
+			"capture_rate": 45,
+			"growth_rate": {"name": "medium-slow"},
+			"habitat": null,
+			"is_mythical": true,
+			"genera": [
+				{"genus": "Pokémon Nouveauté", "language": {"name": "fr"}},
+				{"genus": "New Species Pokémon", "language": {"name": "en"}}
+			],
+			"flavor_text_entries": [
+				{"flavor_text": "So rare that it\nis still said to\fbe a mirage.", "language": {"name": "en"}, "version": {"name": "red"}},
+				{"flavor_text": "Si rare qu'on le dit mirage.", "language": {"name": "fr"}, "version": {"name": "x"}}
+			]
+		}`,
+	})
+
+	species, err := Get[PokemonSpecies](context.Background(), client, "pokemon-species/mew")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if species.Genus() != "New Species Pokémon" {
+		t.Errorf("Expected New Species Pokémon, got %v", species.Genus())
+	}
+
+	if species.Habitat != nil {
+		t.Errorf("Expected no habitat, got %v", species.Habitat)
+	}
+
+	if (PokemonSpecies{}).Genus() != "" {
+		t.Errorf("Expected an empty genus, got %v", (PokemonSpecies{}).Genus())
+	}
+
+	conf := &config{Client: client, Args: []string{"mew"}}
+
+	output := captureOutput(t, func() {
+		if err := commandSpecies(context.Background(), conf); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	expected := []string{
+		"Genus: New Species Pokémon",
+		"Habitat: unknown",
+		" - red: So rare that it is still said to be a mirage.",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected %q in output, got %v", line, output)
+		}
+	}
+
+	if strings.Contains(output, "Si rare") {
+		t.Errorf("Expected only English flavor text, got %v", output)
+	}
+}
+
+func TestHiddenAbilities(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{
+		"ability/intimidate": `{
+			"name": "intimidate",
+			"effect_entries": [{"effect": "Lowers Attack.", "short_effect": "Lowers Attack.", "language": {"name": "en"}}],
+			"pokemon": [
+				{"is_hidden": false, "slot": 1, "pokemon": {"name": "gyarados"}},
+				{"is_hidden": true, "slot": 3, "pokemon": {"name": "growlithe"}}
+			]
+		}`,
+	})
+
+	conf := &config{Client: client, Args: []string{"intimidate"}}
+
+	output := captureOutput(t, func() {
+		if err := commandAbility(context.Background(), conf); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	if !strings.Contains(output, "growlithe (hidden)") {
+		t.Errorf("Expected growlithe to be marked hidden, got %v", output)
+	}
+
+	if strings.Contains(output, "gyarados (hidden)") {
+		t.Errorf("Expected gyarados not to be marked hidden, got %v", output)
+	}
+
+	var pokemon Pokemon
+
+	err := json.Unmarshal([]byte(`{
+		"name": "growlithe",
+		"abilities": [
+			{"ability": {"name": "intimidate"}, "is_hidden": false, "slot": 1},
+			{"ability": {"name": "justified"}, "is_hidden": true, "slot": 3}
+		]
+	}`), &pokemon)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	conf = &config{Args: []string{"growlithe"}, Pokemon: map[string]Pokemon{"growlithe": pokemon}}
+
+	output = captureOutput(t, func() {
+		commandInspectPokemon(conf)
+	})
+
+	if !strings.Contains(output, " - intimidate\n") {
+		t.Errorf("Expected intimidate without a marker, got %v", output)
+	}
+
+	if !strings.Contains(output, " - justified (hidden)\n") {
+		t.Errorf("Expected justified to be marked hidden, got %v", output)
+	}
+}
+
+func TestBerry(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{
+		"berry/cheri": `{
+			"name": "cheri",
+			"growth_time": 3,
+			"max_harvest": 5,
+			"natural_gift_power": 60,
+			"natural_gift_type": {"name": "fire"},
+			"size": 20,
+			"smoothness": 25,
+			"firmness": {"name": "soft"},
+			"flavors": [
+				{"potency": 10, "flavor": {"name": "spicy"}},
+				{"potency": 0, "flavor": {"name": "dry"}}
+			],
+			"item": {"name": "cheri-berry", "url": "https://pokeapi.co/api/v2/item/126/"}
+		}`,
+		"item/126/": `{
+			"name": "cheri-berry",
+			"cost": 20,
+			"fling_power": null,
+			"fling_effect": null,
+			"category": {"name": "medicine"}
+		}`,
+	})
+
+	item, err := Get[Item](context.Background(), client, "item/126/")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if item.FlingPower != nil {
+		t.Errorf("Expected no fling power, got %v", *item.FlingPower)
+	}
+
+	conf := &config{Client: client, Args: []string{"cheri"}}
+
+	output := captureOutput(t, func() {
+		if err := commandBerry(context.Background(), conf); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	expected := []string{
+		"Name: cheri-berry",
+		"Fling power: -",
+		"Firmness: soft",
+		" - spicy: 10",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected %q in output, got %v", line, output)
+		}
+	}
+
+	if strings.Contains(output, "dry") {
+		t.Errorf("Expected zero potency flavors to be left out, got %v", output)
+	}
+}
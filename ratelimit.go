@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket refilled at rate tokens per second, holding
+// at most burst tokens. A nil limiter never waits
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// Overlapping waits are only counted once, see TakeWaited
+	waited      time.Duration
+	waitedUntil time.Time
+}
+
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithLimits returns a limiter with the rate and burst replaced where they are
+// non-zero, a negative rate disables limiting
+func (l *RateLimiter) WithLimits(rate float64, burst int) *RateLimiter {
+	if rate == 0 && burst == 0 {
+		return l
+	}
+
+	currentRate, currentBurst := float64(defaultRequestsPerSecond), defaultBurst
+
+	if l != nil {
+		currentRate, currentBurst = l.rate, int(l.burst)
+	}
+
+	if rate != 0 {
+		currentRate = rate
+	}
+
+	if burst != 0 {
+		currentBurst = burst
+	}
+
+	return NewRateLimiter(currentRate, currentBurst)
+}
+
+// Reserve takes a token and returns how long the caller must wait before
+// using it, the bucket goes negative so queued callers are spaced out
+func (l *RateLimiter) Reserve() time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+
+	// Reservations are handed out in order, so each wait ends no earlier than
+	// the previous one and only the part past it is new
+	if end := now.Add(wait); end.After(l.waitedUntil) {
+		l.waited += end.Sub(later(now, l.waitedUntil))
+		l.waitedUntil = end
+	}
+
+	return wait
+}
+
+// TakeWaited returns how long callers were held up since the last call, with
+// concurrent waits counted once rather than summed
+func (l *RateLimiter) TakeWaited() time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	waited := l.waited
+	l.waited = 0
+
+	return waited
+}
+
+func later(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	wait := l.Reserve()
+
+	if wait <= 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Hand the token back so a cancelled command doesn't delay the next one
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return wait, ctx.Err()
+	case <-timer.C:
+		return wait, nil
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const redisKeyPrefix = "pokedex:"
+
+type RedisCache struct {
+	addr    string
+	timeout time.Duration
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func NewRedisCache(addr string) (*RedisCache, error) {
+	r := &RedisCache{
+		addr:    addr,
+		timeout: 2 * time.Second,
+	}
+
+	if _, err := r.do("PING"); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RedisCache) Get(name string) ([]byte, bool) {
+	data, ok := r.get(name)
+
+	if ok {
+		r.hits.Add(1)
+	} else {
+		r.misses.Add(1)
+	}
+
+	return data, ok
+}
+
+func (r *RedisCache) get(name string) ([]byte, bool) {
+	reply, err := r.do("GET", redisKeyPrefix+name)
+	if err != nil {
+		return nil, false
+	}
+
+	data, ok := reply.([]byte)
+
+	return data, ok
+}
+
+func (r *RedisCache) Delete(name string) {
+	r.do("DEL", redisKeyPrefix+name)
+}
+
+func (r *RedisCache) Stats() CacheStats {
+	stats := CacheStats{
+		Hits:   r.hits.Load(),
+		Misses: r.misses.Load(),
+	}
+
+	r.Range(func(name string, data []byte, expires time.Time) bool {
+		stats.Entries++
+		stats.Bytes += int64(len(data))
+
+		return true
+	})
+
+	return stats
+}
+
+func (r *RedisCache) Set(name string, data []byte, duration time.Duration) {
+	// Like the other backends, a non-positive duration is already expired
+	if duration <= 0 {
+		r.Delete(name)
+
+		return
+	}
+
+	// Redis rejects PX 0
+	ms := max(duration.Milliseconds(), 1)
+
+	r.do("SET", redisKeyPrefix+name, string(data), "PX", strconv.FormatInt(ms, 10))
+}
+
+func (r *RedisCache) Range(fn func(name string, data []byte, expires time.Time) bool) {
+	cursor := "0"
+
+	for {
+		reply, err := r.do("SCAN", cursor, "MATCH", redisKeyPrefix+"*", "COUNT", "100")
+		if err != nil {
+			return
+		}
+
+		parts, ok := reply.([]any)
+		if !ok || len(parts) != 2 {
+			return
+		}
+
+		next, _ := parts[0].([]byte)
+		keys, _ := parts[1].([]any)
+
+		for _, key := range keys {
+			name, _ := key.([]byte)
+
+			data, ok := r.get(strings.TrimPrefix(string(name), redisKeyPrefix))
+			if !ok {
+				continue
+			}
+
+			// -1 means the key has no expiry
+			ttl, err := r.do("PTTL", string(name))
+			if err != nil {
+				continue
+			}
+
+			expires := time.Now().Add(NeverExpire)
+
+			if ms, ok := ttl.(int64); ok && ms >= 0 {
+				expires = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+
+			if !fn(strings.TrimPrefix(string(name), redisKeyPrefix), data, expires) {
+				return
+			}
+		}
+
+		cursor = string(next)
+
+		if cursor == "0" {
+			return
+		}
+	}
+}
+
+func (r *RedisCache) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		return nil
+	}
+
+	err := r.conn.Close()
+
+	r.conn = nil
+
+	return err
+}
+
+// do sends one command and reads its reply, reconnecting if the previous
+// connection was dropped
+func (r *RedisCache) do(args ...string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		conn, err := net.DialTimeout("tcp", r.addr, r.timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		r.conn = conn
+		r.reader = bufio.NewReader(conn)
+	}
+
+	r.conn.SetDeadline(time.Now().Add(r.timeout))
+
+	reply, err := r.roundTrip(args)
+
+	var replyErr redisError
+
+	if err != nil && !errors.As(err, &replyErr) {
+		r.conn.Close()
+		r.conn = nil
+	}
+
+	return reply, err
+}
+
+func (r *RedisCache) roundTrip(args []string) (any, error) {
+	if _, err := r.conn.Write(encodeRESP(args)); err != nil {
+		return nil, err
+	}
+
+	return readRESP(r.reader)
+}
+
+func encodeRESP(args []string) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	return []byte(b.String())
+}
+
+// readRESP decodes a single RESP value: simple strings as string, integers
+// as int64, bulk strings as []byte, arrays as []any and nil bulks as nil
+func readRESP(reader *bufio.Reader) (any, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		if size < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, size+2)
+
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		if count < 0 {
+			return nil, nil
+		}
+
+		items := make([]any, 0, count)
+
+		for i := 0; i < count; i++ {
+			item, err := readRESP(reader)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const mapPageSize = 20
+
+type Region struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	Locations      []NamedAPIResource `json:"locations"`
+	MainGeneration NamedAPIResource   `json:"main_generation"`
+	VersionGroups  []NamedAPIResource `json:"version_groups"`
+}
+
+type Location struct {
+	ID     int                `json:"id"`
+	Name   string             `json:"name"`
+	Region *NamedAPIResource  `json:"region"`
+	Areas  []NamedAPIResource `json:"areas"`
+}
+
+func commandRegions(ctx context.Context, c *config) error {
+	regions, err := Get[NamedAPIResourceList](ctx, c.Client, c.Client.URL("region/"))
+	if err != nil {
+		return err
+	}
+
+	for _, region := range regions.Results {
+		if region.Name == c.Map.Region {
+			fmt.Println(region.Name + " (current)")
+		} else {
+			fmt.Println(region.Name)
+		}
+	}
+
+	return nil
+}
+
+func commandRegion(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: region <name>, or region none to map every region")
+
+		return nil
+	}
+
+	if c.Args[0] == "none" {
+		c.Map.Region = ""
+
+		fmt.Println("map now covers every region")
+
+		return nil
+	}
+
+	region, err := Get[Region](ctx, c.Client, c.Client.URL("region", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	c.Map.Region = region.Name
+	c.Map.Offset = 0
+
+	fmt.Println("Region: " + region.Name)
+	fmt.Println("Generation: " + region.MainGeneration.Name)
+
+	names := make([]string, 0, len(region.Locations))
+
+	for _, location := range region.Locations {
+		names = append(names, location.Name)
+	}
+
+	fmt.Println("Locations (" + fmt.Sprint(len(names)) + "):")
+	fmt.Print(wrapList(names, 80, "  "))
+
+	fmt.Println("map and mapb now page through the areas of " + region.Name)
+
+	return nil
+}
+
+func commandLocation(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: location <name>")
+
+		return nil
+	}
+
+	location, err := Get[Location](ctx, c.Client, c.Client.URL("location", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Location: " + location.Name)
+
+	if location.Region != nil {
+		fmt.Println("Region: " + location.Region.Name)
+	}
+
+	if len(location.Areas) == 0 {
+		fmt.Println("No areas to explore here")
+
+		return nil
+	}
+
+	fmt.Println("Areas:")
+
+	for _, area := range location.Areas {
+		fmt.Println(" - " + area.Name)
+	}
+
+	return nil
+}
+
+// regionMapPage prints the areas of one page of the current region's
+// locations, fetching the locations concurrently
+func regionMapPage(ctx context.Context, c *config, offset int) error {
+	region, err := Get[Region](ctx, c.Client, c.Client.URL("region", c.Map.Region))
+	if err != nil {
+		return err
+	}
+
+	end := min(offset+mapPageSize, len(region.Locations))
+	page := region.Locations[offset:end]
+
+	locations := make([]Location, len(page))
+	errs := make([]error, len(page))
+
+	var wg sync.WaitGroup
+
+	for i, resource := range page {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			locations[i], errs[i] = Get[Location](ctx, c.Client, resource.URL)
+		}()
+	}
+
+	wg.Wait()
+
+	for i, location := range locations {
+		if errs[i] != nil {
+			return errs[i]
+		}
+
+		for _, area := range location.Areas {
+			fmt.Println(area.Name)
+		}
+	}
+
+	return nil
+}
+
+func regionMapForward(ctx context.Context, c *config) error {
+	region, err := Get[Region](ctx, c.Client, c.Client.URL("region", c.Map.Region))
+	if err != nil {
+		return err
+	}
+
+	if c.Map.Offset >= len(region.Locations) {
+		fmt.Println("You're on the last page")
+
+		return nil
+	}
+
+	if err := regionMapPage(ctx, c, c.Map.Offset); err != nil {
+		return err
+	}
+
+	c.Map.Offset += mapPageSize
+
+	return nil
+}
+
+func regionMapBack(ctx context.Context, c *config) error {
+	// Offset points past the page on screen, so the previous page is two back
+	previous := c.Map.Offset - 2*mapPageSize
+
+	if previous < 0 {
+		fmt.Println("You're on the first page")
+
+		return nil
+	}
+
+	if err := regionMapPage(ctx, c, previous); err != nil {
+		return err
+	}
+
+	c.Map.Offset -= mapPageSize
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+}
+
+// Retryable reports whether err is worth another attempt: network failures,
+// 5xx responses and rate limiting, as long as any Retry-After is within MaxDelay
+func (p RetryPolicy) Retryable(err error) bool {
+	var network *NetworkError
+	var rateLimited *RateLimitedError
+	var server *ServerError
+
+	switch {
+	case errors.As(err, &network):
+		return true
+	case errors.As(err, &rateLimited):
+		return p.MaxDelay <= 0 || rateLimited.RetryAfter <= p.MaxDelay
+	case errors.As(err, &server):
+		return server.StatusCode >= http.StatusInternalServerError && (p.MaxDelay <= 0 || server.RetryAfter <= p.MaxDelay)
+	default:
+		return false
+	}
+}
+
+// Delay is how long to wait before the given retry (1 for the first retry),
+// an exponential backoff with jitter unless the server sent Retry-After
+func (p RetryPolicy) Delay(retry int, err error) time.Duration {
+	if wait := retryAfter(err); wait > 0 {
+		if p.MaxDelay > 0 {
+			return min(wait, p.MaxDelay)
+		}
+
+		return wait
+	}
+
+	delay := p.BaseDelay << (retry - 1)
+
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter keeps at least half the backoff but spreads retries out
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func retryAfter(err error) time.Duration {
+	var rateLimited *RateLimitedError
+	var server *ServerError
+
+	switch {
+	case errors.As(err, &rateLimited):
+		return rateLimited.RetryAfter
+	case errors.As(err, &server):
+		return server.RetryAfter
+	default:
+		return 0
+	}
+}
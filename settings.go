@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Settings struct {
+	BaseURL string            `json:"base_url"`
+	Timeout string            `json:"timeout"`
+	TTL     map[string]string `json:"ttl"`
+	Retry   struct {
+		MaxAttempts int    `json:"max_attempts"`
+		BaseDelay   string `json:"base_delay"`
+		MaxDelay    string `json:"max_delay"`
+	} `json:"retry"`
+	RateLimit struct {
+		RequestsPerSecond float64 `json:"requests_per_second"`
+		Burst             int     `json:"burst"`
+	} `json:"rate_limit"`
+}
+
+func defaultSettingsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "pokedex", "config.json")
+}
+
+// LoadSettings reads a JSON settings file, a missing file is not an error
+func LoadSettings(path string) (Settings, error) {
+	var settings Settings
+
+	if path == "" {
+		return settings, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings, nil
+	}
+
+	if err != nil {
+		return settings, err
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, err
+	}
+
+	return settings, nil
+}
+
+func (s Settings) Apply(client *Client) error {
+	if s.BaseURL != "" {
+		client.BaseURL = s.BaseURL
+	}
+
+	if s.Timeout != "" {
+		timeout, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout: %w", err)
+		}
+
+		client.Timeout = timeout
+	}
+
+	if err := client.TTL.Apply(s.TTL); err != nil {
+		return err
+	}
+
+	client.Limiter = client.Limiter.WithLimits(s.RateLimit.RequestsPerSecond, s.RateLimit.Burst)
+
+	if s.Retry.MaxAttempts > 0 {
+		client.Retry.MaxAttempts = s.Retry.MaxAttempts
+	}
+
+	if s.Retry.BaseDelay != "" {
+		delay, err := time.ParseDuration(s.Retry.BaseDelay)
+		if err != nil {
+			return fmt.Errorf("retry base_delay: %w", err)
+		}
+
+		client.Retry.BaseDelay = delay
+	}
+
+	if s.Retry.MaxDelay != "" {
+		delay, err := time.ParseDuration(s.Retry.MaxDelay)
+		if err != nil {
+			return fmt.Errorf("retry max_delay: %w", err)
+		}
+
+		client.Retry.MaxDelay = delay
+	}
+
+	return nil
+}
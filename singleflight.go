@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+type flightCall struct {
+	done    chan struct{}
+	data    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup coalesces concurrent fetches of the same key so that only one
+// of them does the work and the rest wait for its result, or for their own
+// context to end. The work is only cancelled once every caller has given up,
+// so one cancelled command doesn't fail the others
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	call, ok := g.calls[key]
+	if !ok {
+		var flightCtx context.Context
+
+		call = &flightCall{done: make(chan struct{})}
+		flightCtx, call.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+		g.calls[key] = call
+
+		go func() {
+			call.data, call.err = fn(flightCtx)
+
+			g.mu.Lock()
+			g.forget(key, call)
+			g.mu.Unlock()
+
+			call.cancel()
+			close(call.done)
+		}()
+	}
+
+	call.waiters++
+
+	g.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		g.mu.Lock()
+
+		call.waiters--
+
+		// Later callers start a fresh fetch instead of joining a cancelled one
+		if call.waiters == 0 {
+			g.forget(key, call)
+			call.cancel()
+		}
+
+		g.mu.Unlock()
+
+		return nil, ctx.Err()
+	case <-call.done:
+		return call.data, call.err
+	}
+}
+
+func (g *flightGroup) forget(key string, call *flightCall) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const snapshotVersion = 2
+
+type snapshotHeader struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+}
+
+type snapshotEntry struct {
+	Key     string          `json:"key"`
+	Expires time.Time       `json:"expires"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ExportSnapshot writes every live entry as a gzipped stream of JSON values,
+// a header followed by one value per entry
+func ExportSnapshot(w io.Writer, cache CacheBackend) (int, error) {
+	ranger, ok := cache.(CacheRanger)
+	if !ok {
+		return 0, errors.New("this cache backend cannot be exported")
+	}
+
+	zw := gzip.NewWriter(w)
+	encoder := json.NewEncoder(zw)
+
+	if err := encoder.Encode(snapshotHeader{Version: snapshotVersion, Created: time.Now()}); err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	var err error
+
+	ranger.Range(func(name string, data []byte, expires time.Time) bool {
+		err = encoder.Encode(snapshotEntry{Key: name, Expires: expires, Data: data})
+		if err != nil {
+			return false
+		}
+
+		count++
+
+		return true
+	})
+
+	if err != nil {
+		return count, err
+	}
+
+	return count, zw.Close()
+}
+
+func ImportSnapshot(r io.Reader, cache CacheBackend) (imported int, skipped int, err error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defer zr.Close()
+
+	decoder := json.NewDecoder(zr)
+
+	var header snapshotHeader
+
+	if err := decoder.Decode(&header); err != nil {
+		return 0, 0, fmt.Errorf("invalid snapshot header: %w", err)
+	}
+
+	if header.Version != snapshotVersion {
+		return 0, 0, fmt.Errorf("unsupported snapshot version %d", header.Version)
+	}
+
+	for {
+		var entry snapshotEntry
+
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return imported, skipped, fmt.Errorf("invalid snapshot entry: %w", err)
+		}
+
+		remaining := time.Until(entry.Expires)
+
+		if entry.Key == "" || len(entry.Data) == 0 || remaining <= 0 {
+			skipped++
+
+			continue
+		}
+
+		cache.Set(entry.Key, entry.Data, remaining)
+
+		imported++
+	}
+
+	return imported, skipped, nil
+}
+
+func commandExportCache(conf *config) error {
+	if len(conf.RawArgs) < 1 {
+		fmt.Println("Usage: export <file>")
+
+		return nil
+	}
+
+	file, err := os.Create(conf.RawArgs[0])
+	if err != nil {
+		return err
+	}
+
+	count, err := ExportSnapshot(file, conf.Client.Cache)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Exported " + fmt.Sprint(count) + " entries to " + conf.RawArgs[0])
+
+	return nil
+}
+
+func commandImportCache(conf *config) error {
+	if len(conf.RawArgs) < 1 {
+		fmt.Println("Usage: import <file>")
+
+		return nil
+	}
+
+	file, err := os.Open(conf.RawArgs[0])
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	imported, skipped, err := ImportSnapshot(file, conf.Client.Cache)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Imported " + fmt.Sprint(imported) + " entries, skipped " + fmt.Sprint(skipped))
+
+	return nil
+}
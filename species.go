@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const englishLanguage = "en"
+
+type PokemonSpecies struct {
+	ID             int                `json:"id"`
+	Name           string             `json:"name"`
+	BaseHappiness  int                `json:"base_happiness"`
+	CaptureRate    int                `json:"capture_rate"`
+	EggGroups      []NamedAPIResource `json:"egg_groups"`
+	EvolutionChain struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	FlavorTextEntries []struct {
+		FlavorText string           `json:"flavor_text"`
+		Language   NamedAPIResource `json:"language"`
+		Version    NamedAPIResource `json:"version"`
+	} `json:"flavor_text_entries"`
+	Genera []struct {
+		Genus    string           `json:"genus"`
+		Language NamedAPIResource `json:"language"`
+	} `json:"genera"`
+	GrowthRate  NamedAPIResource  `json:"growth_rate"`
+	Habitat     *NamedAPIResource `json:"habitat"`
+	IsBaby      bool              `json:"is_baby"`
+	IsLegendary bool              `json:"is_legendary"`
+	IsMythical  bool              `json:"is_mythical"`
+}
+
+func (s PokemonSpecies) Genus() string {
+	for _, genus := range s.Genera {
+		if genus.Language.Name == englishLanguage {
+			return genus.Genus
+		}
+	}
+
+	return ""
+}
+
+// PokeAPI flavor text keeps the line breaks and form feeds of the games
+func cleanFlavorText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func commandSpecies(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: species <pokemon>")
+
+		return nil
+	}
+
+	species, err := Get[PokemonSpecies](ctx, c.Client, c.Client.URL("pokemon-species", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Name: " + species.Name)
+	fmt.Println("Genus: " + species.Genus())
+
+	fmt.Println("Capture rate: " + fmt.Sprint(species.CaptureRate))
+	fmt.Println("Base happiness: " + fmt.Sprint(species.BaseHappiness))
+	fmt.Println("Growth rate: " + species.GrowthRate.Name)
+
+	if species.Habitat != nil {
+		fmt.Println("Habitat: " + species.Habitat.Name)
+	} else {
+		fmt.Println("Habitat: unknown")
+	}
+
+	fmt.Println("Legendary: " + fmt.Sprint(species.IsLegendary))
+	fmt.Println("Mythical: " + fmt.Sprint(species.IsMythical))
+
+	fmt.Println("Egg groups:")
+
+	for _, group := range species.EggGroups {
+		fmt.Println(" - " + group.Name)
+	}
+
+	fmt.Println("Flavor text:")
+
+	for _, entry := range species.FlavorTextEntries {
+		if entry.Language.Name == englishLanguage {
+			fmt.Println(" - " + entry.Version.Name + ": " + cleanFlavorText(entry.FlavorText))
+		}
+	}
+
+	return nil
+}
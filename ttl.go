@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NeverExpire is long enough to outlive any cache it is stored in
+const NeverExpire = 100 * 365 * 24 * time.Hour
+
+const (
+	cacheTTL = 1 * time.Hour
+
+	// Misspelt names are usually retried quickly, but not forever
+	notFoundTTL = 5 * time.Minute
+)
+
+type TTLPolicy struct {
+	Default  time.Duration
+	List     time.Duration
+	NotFound time.Duration
+	Kinds    map[string]time.Duration
+}
+
+func DefaultTTLPolicy() TTLPolicy {
+	return TTLPolicy{
+		Default:  24 * time.Hour,
+		List:     cacheTTL,
+		NotFound: notFoundTTL,
+		Kinds: map[string]time.Duration{
+			"pokemon":         NeverExpire,
+			"pokemon-species": NeverExpire,
+			"location":        NeverExpire,
+			"location-area":   NeverExpire,
+		},
+	}
+}
+
+// Apply overrides the policy from "key=duration" pairs, where key is
+// default, list, not-found or a resource kind and duration may be "never"
+func (p *TTLPolicy) Apply(overrides map[string]string) error {
+	for key, value := range overrides {
+		duration, err := parseTTL(value)
+		if err != nil {
+			return fmt.Errorf("ttl for %s: %w", key, err)
+		}
+
+		switch key {
+		case "default":
+			p.Default = duration
+		case "list":
+			p.List = duration
+		case "not-found":
+			p.NotFound = duration
+		default:
+			if p.Kinds == nil {
+				p.Kinds = make(map[string]time.Duration)
+			}
+
+			p.Kinds[key] = duration
+		}
+	}
+
+	return nil
+}
+
+func (p TTLPolicy) For(rawURL string) time.Duration {
+	kind, list := resourceKind(rawURL)
+
+	if list && p.List > 0 {
+		return p.List
+	}
+
+	if duration, ok := p.Kinds[kind]; ok && !list && duration > 0 {
+		return duration
+	}
+
+	if p.Default > 0 {
+		return p.Default
+	}
+
+	return cacheTTL
+}
+
+func (p TTLPolicy) ForNotFound() time.Duration {
+	if p.NotFound > 0 {
+		return p.NotFound
+	}
+
+	return notFoundTTL
+}
+
+func parseTTL(value string) (time.Duration, error) {
+	if strings.EqualFold(value, "never") {
+		return NeverExpire, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("duration must be positive, got %s", value)
+	}
+
+	return duration, nil
+}
+
+// ParseTTLSpec reads the POKEDEX_TTL format: "pokemon=never,list=10m"
+func ParseTTLSpec(spec string) (map[string]string, error) {
+	overrides := make(map[string]string)
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+
+		if pair == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ttl %q, expected kind=duration", pair)
+		}
+
+		overrides[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return overrides, nil
+}
+
+// resourceKind pulls the resource out of .../api/v2/{resource}/{id or name}/,
+// reporting whether the URL is a paginated list rather than a single entry
+func resourceKind(rawURL string) (string, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+
+	for i, segment := range segments {
+		if segment == "api" && i+2 < len(segments) {
+			return segments[i+2], i+3 == len(segments)
+		}
+	}
+
+	if len(segments) >= 2 {
+		return segments[len(segments)-2], false
+	}
+
+	return "", false
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Type struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	DamageRelations struct {
+		DoubleDamageFrom []NamedAPIResource `json:"double_damage_from"`
+		DoubleDamageTo   []NamedAPIResource `json:"double_damage_to"`
+		HalfDamageFrom   []NamedAPIResource `json:"half_damage_from"`
+		HalfDamageTo     []NamedAPIResource `json:"half_damage_to"`
+		NoDamageFrom     []NamedAPIResource `json:"no_damage_from"`
+		NoDamageTo       []NamedAPIResource `json:"no_damage_to"`
+	} `json:"damage_relations"`
+}
+
+// Multiplier is the damage this type deals to a single defending type
+func (t Type) Multiplier(defending string) float64 {
+	relations := t.DamageRelations
+
+	switch {
+	case containsResource(relations.NoDamageTo, defending):
+		return 0
+	case containsResource(relations.DoubleDamageTo, defending):
+		return 2
+	case containsResource(relations.HalfDamageTo, defending):
+		return 0.5
+	default:
+		return 1
+	}
+}
+
+// CombinedMultiplier multiplies over every defending type, so dual types
+// stack to 4x or 0.25x
+func (t Type) CombinedMultiplier(defending []string) float64 {
+	multiplier := 1.0
+
+	for _, name := range defending {
+		multiplier *= t.Multiplier(name)
+	}
+
+	return multiplier
+}
+
+func containsResource(resources []NamedAPIResource, name string) bool {
+	for _, resource := range resources {
+		if resource.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatMultiplier(multiplier float64) string {
+	return strconv.FormatFloat(multiplier, 'g', -1, 64) + "x"
+}
+
+func describeEffectiveness(multiplier float64) string {
+	switch {
+	case multiplier == 0:
+		return "no effect (immune)"
+	case multiplier > 1:
+		return "super effective"
+	case multiplier < 1:
+		return "not very effective"
+	default:
+		return "normal damage"
+	}
+}
+
+func pokemonTypeNames(pokemon Pokemon) []string {
+	names := make([]string, 0, len(pokemon.Types))
+
+	for _, type_ := range pokemon.Types {
+		names = append(names, type_.Type.Name)
+	}
+
+	return names
+}
+
+// defenderTypes resolves a caught Pokemon, a type list like "water/flying"
+// or any other Pokemon by name
+func defenderTypes(ctx context.Context, c *config, defender string) ([]string, error) {
+	if pokemon, ok := c.Pokemon[defender]; ok {
+		return pokemonTypeNames(pokemon), nil
+	}
+
+	names := strings.Split(defender, "/")
+
+	for _, name := range names {
+		_, err := Get[Type](ctx, c.Client, c.Client.URL("type", name))
+
+		var notFound *NotFoundError
+
+		if errors.As(err, &notFound) && len(names) == 1 {
+			pokemon, err := Get[Pokemon](ctx, c.Client, c.Client.URL("pokemon", defender))
+			if err != nil {
+				return nil, err
+			}
+
+			return pokemonTypeNames(pokemon), nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
+
+func commandMatchup(ctx context.Context, c *config) error {
+	if len(c.Args) < 2 {
+		fmt.Println("Usage: matchup <attacking-type> <pokemon or type[/type]>")
+
+		return nil
+	}
+
+	attacking, err := Get[Type](ctx, c.Client, c.Client.URL("type", c.Args[0]))
+	if err != nil {
+		return err
+	}
+
+	defending, err := defenderTypes(ctx, c, c.Args[1])
+	if err != nil {
+		return err
+	}
+
+	multiplier := attacking.CombinedMultiplier(defending)
+
+	fmt.Println(attacking.Name + " vs " + c.Args[1] + " (" + strings.Join(defending, "/") + "): " + formatMultiplier(multiplier) + ", " + describeEffectiveness(multiplier))
+
+	if len(defending) > 1 {
+		for _, name := range defending {
+			fmt.Println(" - " + name + ": " + formatMultiplier(attacking.Multiplier(name)))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type LocationAreaEncounter struct {
+	LocationArea   NamedAPIResource `json:"location_area"`
+	VersionDetails []struct {
+		MaxChance        int               `json:"max_chance"`
+		Version          NamedAPIResource  `json:"version"`
+		EncounterDetails []EncounterDetail `json:"encounter_details"`
+	} `json:"version_details"`
+}
+
+type encounterRow struct {
+	Area       string
+	Method     string
+	MinLevel   int
+	MaxLevel   int
+	Chance     int
+	Conditions string
+}
+
+type versionEncounters struct {
+	Version string
+	Rows    []encounterRow
+}
+
+// groupEncounters merges the details for each area, method and set of
+// conditions per version, ordered by version name and then by how likely the encounter is
+func groupEncounters(encounters []LocationAreaEncounter) []versionEncounters {
+	rowsByVersion := make(map[string]map[string]*encounterRow)
+
+	for _, encounter := range encounters {
+		for _, version := range encounter.VersionDetails {
+			rows, ok := rowsByVersion[version.Version.Name]
+			if !ok {
+				rows = make(map[string]*encounterRow)
+				rowsByVersion[version.Version.Name] = rows
+			}
+
+			for _, detail := range version.EncounterDetails {
+				conditions := make([]string, 0, len(detail.ConditionValues))
+
+				for _, condition := range detail.ConditionValues {
+					conditions = append(conditions, condition.Name)
+				}
+
+				sort.Strings(conditions)
+
+				// Chances only add up within the same conditions, e.g. morning
+				// and night odds are alternatives
+				key := encounter.LocationArea.Name + "/" + detail.Method.Name + "/" + strings.Join(conditions, ",")
+
+				row, ok := rows[key]
+				if !ok {
+					row = &encounterRow{
+						Area:       encounter.LocationArea.Name,
+						Method:     detail.Method.Name,
+						MinLevel:   detail.MinLevel,
+						MaxLevel:   detail.MaxLevel,
+						Conditions: strings.Join(conditions, ", "),
+					}
+
+					rows[key] = row
+				}
+
+				row.MinLevel = min(row.MinLevel, detail.MinLevel)
+				row.MaxLevel = max(row.MaxLevel, detail.MaxLevel)
+				row.Chance += detail.Chance
+			}
+		}
+	}
+
+	grouped := make([]versionEncounters, 0, len(rowsByVersion))
+
+	for version, rows := range rowsByVersion {
+		group := versionEncounters{Version: version}
+
+		for _, row := range rows {
+			group.Rows = append(group.Rows, *row)
+		}
+
+		sort.Slice(group.Rows, func(i, j int) bool {
+			if group.Rows[i].Chance != group.Rows[j].Chance {
+				return group.Rows[i].Chance > group.Rows[j].Chance
+			}
+
+			return group.Rows[i].Area+group.Rows[i].Method+group.Rows[i].Conditions < group.Rows[j].Area+group.Rows[j].Method+group.Rows[j].Conditions
+		})
+
+		grouped = append(grouped, group)
+	}
+
+	sort.Slice(grouped, func(i, j int) bool {
+		return grouped[i].Version < grouped[j].Version
+	})
+
+	return grouped
+}
+
+func formatLevels(minLevel int, maxLevel int) string {
+	if minLevel == maxLevel {
+		return "lv " + fmt.Sprint(minLevel)
+	}
+
+	return "lv " + fmt.Sprint(minLevel) + "-" + fmt.Sprint(maxLevel)
+}
+
+func commandWhere(ctx context.Context, c *config) error {
+	if len(c.Args) < 1 {
+		fmt.Println("Usage: where <pokemon>")
+
+		return nil
+	}
+
+	pokemon, ok := c.Pokemon[c.Args[0]]
+
+	if !ok {
+		var err error
+
+		pokemon, err = Get[Pokemon](ctx, c.Client, c.Client.URL("pokemon", c.Args[0]))
+		if err != nil {
+			return err
+		}
+	}
+
+	encounters, err := Get[[]LocationAreaEncounter](ctx, c.Client, pokemon.LocationAreaEncounters)
+	if err != nil {
+		return err
+	}
+
+	if len(encounters) == 0 {
+		fmt.Println(pokemon.Name + " can't be found in the wild")
+
+		return nil
+	}
+
+	for _, group := range groupEncounters(encounters) {
+		fmt.Println(group.Version + ":")
+
+		for _, row := range group.Rows {
+			line := fmt.Sprintf("  %-40s %-14s %-9s %3d%%", row.Area, row.Method, formatLevels(row.MinLevel, row.MaxLevel), row.Chance)
+
+			if row.Conditions != "" {
+				line += "  " + row.Conditions
+			}
+
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}